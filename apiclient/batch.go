@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	R "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+)
+
+// defaultBatchConcurrency represents the number of commands processed in parallel by default
+const defaultBatchConcurrency = 10
+
+// ErrBatchAborted is used for commands of a batch that have not been requested
+// as the batch got aborted due to a failed command in fail-fast mode
+var ErrBatchAborted = errors.New("command skipped as batch got aborted")
+
+// BatchOptions represents the options for processing a batch of API commands.
+type BatchOptions struct {
+	Concurrency    int                       // Concurrency is the maximum number of commands in flight; defaults to 10.
+	FailFast       bool                      // FailFast indicates whether to abort the batch on the first failed command.
+	OnProgress     func(done int, total int) // OnProgress is called (serialized) whenever a command got processed.
+	RequestOptions *RequestOptions           // RequestOptions are applied to every command of the batch.
+}
+
+// BatchResult represents the outcome of a single command of a batch.
+// Response is nil for commands skipped due to an aborted batch.
+type BatchResult struct {
+	Response *R.Response
+	Err      error
+}
+
+// BatchError is returned when at least one command of a batch failed.
+// It covers the errors by index of the related command.
+type BatchError struct {
+	Total  int
+	Errors map[int]error
+}
+
+// Error method to return the error message
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d batch commands failed", len(e.Errors), e.Total)
+}
+
+// Unwrap method to return the underlying errors ordered by command index
+func (e *BatchError) Unwrap() []error {
+	idxs := make([]int, 0, len(e.Errors))
+	for idx := range e.Errors {
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+	errs := make([]error, 0, len(idxs))
+	for _, idx := range idxs {
+		errs = append(errs, e.Errors[idx])
+	}
+	return errs
+}
+
+// RequestBatch method to perform the given independent API commands in parallel with bounded
+// concurrency. The results are returned in the order of the given commands. A *BatchError is
+// returned if any command failed.
+func (cl *APIClient) RequestBatch(ctx context.Context, cmds []map[string]interface{}, opts BatchOptions) ([]BatchResult, error) {
+	total := len(cmds)
+	results := make([]BatchResult, total)
+	if total == 0 {
+		return results, nil
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > total {
+		concurrency = total
+	}
+	reqOpts := opts.RequestOptions
+	if reqOpts == nil {
+		reqOpts = NewRequestOptions()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	done := 0
+	failed := false
+	idxs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxs {
+				r, err := cl.DoContext(ctx, cmds[idx], reqOpts)
+				results[idx] = BatchResult{Response: r, Err: err}
+				mu.Lock()
+				done++
+				if err != nil && opts.FailFast && !failed {
+					failed = true
+					cancel()
+				}
+				if opts.OnProgress != nil {
+					opts.OnProgress(done, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for idx := range cmds {
+		mu.Lock()
+		aborted := failed
+		mu.Unlock()
+		if aborted {
+			results[idx] = BatchResult{Err: ErrBatchAborted}
+			continue
+		}
+		idxs <- idx
+	}
+	close(idxs)
+	wg.Wait()
+
+	errs := map[int]error{}
+	for idx, res := range results {
+		if res.Err != nil {
+			errs[idx] = res.Err
+		}
+	}
+	if len(errs) > 0 {
+		return results, &BatchError{Total: total, Errors: errs}
+	}
+	return results, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"fmt"
+
+	R "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+)
+
+// TransportError is returned when the HTTP communication with the API failed,
+// e.g. because of DNS resolution, connection or TLS failures or a cancelled context.
+type TransportError struct {
+	URL string
+	Err error
+}
+
+// Error method to return the error message
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("http communication with %s failed: %v", e.URL, e.Err)
+}
+
+// Unwrap method to return the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError is returned when the API endpoint answered with an unexpected HTTP status code.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// Error method to return the error message
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected http status from %s: %s", e.URL, e.Status)
+}
+
+// APIError is returned when the API processed the command, but did not return a success case.
+type APIError struct {
+	Code        int
+	Description string
+	Command     map[string]string
+}
+
+// newAPIError method to create an APIError out of the given API response
+func newAPIError(r *R.Response) *APIError {
+	return &APIError{
+		Code:        r.GetCode(),
+		Description: r.GetDescription(),
+		Command:     r.GetCommand(),
+	}
+}
+
+// Error method to return the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("command %s failed: %d %s", e.Command["COMMAND"], e.Code, e.Description)
+}
+
+// IsTmpError method to check if the error represents a temporary error case
+func (e *APIError) IsTmpError() bool {
+	return (e.Code >= 400 && e.Code <= 499)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+
+	R "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+)
+
+// Handler represents a function processing the given flattened API command.
+// It has to return a non-nil Response, even in error case.
+type Handler func(ctx context.Context, cmd map[string]string) (*R.Response, error)
+
+// Middleware represents an interceptor wrapping a Handler. It is called after command
+// flattening and IDN conversion and before the command is requested to the API
+// (including retries and rate limiting). A Middleware may inspect or rewrite the command,
+// inspect or replace the Response or skip calling next at all.
+type Middleware func(next Handler) Handler
+
+// Use method to register the given middlewares. The first registered middleware is the
+// outermost one, i.e. it sees the command first and the response last.
+func (cl *APIClient) Use(middlewares ...Middleware) *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	mws := make([]Middleware, 0, len(cl.middlewares)+len(middlewares))
+	mws = append(mws, cl.middlewares...)
+	cl.middlewares = append(mws, middlewares...)
+	return cl
+}
+
+// ResetMiddlewares method to remove all registered middlewares
+func (cl *APIClient) ResetMiddlewares() *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.middlewares = nil
+	return cl
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/record"
+	R "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+)
+
+// PaginationOptions represents the options for iterating the pages of a list query.
+type PaginationOptions struct {
+	Prefetch       bool            // Prefetch indicates whether to request the next page while the current one is processed.
+	RequestOptions *RequestOptions // RequestOptions are applied to every page request.
+}
+
+// pageResult represents the outcome of a page request
+type pageResult struct {
+	r   *R.Response
+	err error
+}
+
+// ResponsePages method to lazily iterate all pages of the given list query command.
+// Pages are requested one by one while iterating, starting at FIRST (0 by default).
+// Iteration stops after the last page or at the first failed page request; in the latter
+// case, the error (and the failed Response, if any) is yielded as last element.
+func (cl *APIClient) ResponsePages(ctx context.Context, cmd map[string]string, opts ...*PaginationOptions) iter.Seq2[*R.Response, error] {
+	options := &PaginationOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
+	}
+	reqOpts := options.RequestOptions
+	if reqOpts == nil {
+		reqOpts = NewRequestOptions()
+	}
+	return func(yield func(*R.Response, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		fetch := func(mycmd map[string]interface{}) <-chan pageResult {
+			ch := make(chan pageResult, 1)
+			go func() {
+				r, err := cl.DoContext(ctx, mycmd, reqOpts)
+				ch <- pageResult{r: r, err: err}
+			}()
+			return ch
+		}
+
+		mycmd := map[string]interface{}{"FIRST": "0"}
+		for k, v := range cmd {
+			mycmd[k] = v
+		}
+		pending := fetch(mycmd)
+		for {
+			page := <-pending
+			if page.err != nil {
+				yield(page.r, page.err)
+				return
+			}
+			var next map[string]interface{}
+			if page.r.GetRecordsCount() > 0 {
+				var err error
+				next, err = nextPageCommand(page.r)
+				if err != nil && !errors.Is(err, ErrNoFurtherPages) {
+					if yield(page.r, nil) {
+						yield(nil, err)
+					}
+					return
+				}
+			}
+			pending = nil
+			if next != nil && options.Prefetch {
+				pending = fetch(next)
+			}
+			if !yield(page.r, nil) || next == nil {
+				return
+			}
+			if pending == nil {
+				pending = fetch(next)
+			}
+		}
+	}
+}
+
+// Records method to lazily iterate all records of the given list query command across pages.
+// See ResponsePages for details; an error that stopped iteration is yielded with a nil record.
+func (cl *APIClient) Records(ctx context.Context, cmd map[string]string, opts ...*PaginationOptions) iter.Seq2[*record.Record, error] {
+	return func(yield func(*record.Record, error) bool) {
+		for page, err := range cl.ResponsePages(ctx, cmd, opts...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := 0; i < page.GetRecordsCount(); i++ {
+				if !yield(page.GetRecord(i), nil) {
+					return
+				}
+			}
+		}
+	}
+}
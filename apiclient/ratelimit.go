@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+	"strings"
+
+	RL "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/ratelimiter"
+)
+
+// SetRateLimiter method to set the rate limiter applied to all API requests; use nil to disable it
+func (cl *APIClient) SetRateLimiter(rl *RL.RateLimiter) *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.rateLimiter = rl
+	return cl
+}
+
+// SetCommandRateLimiter method to set the rate limiter applied to API requests of the given
+// command (case-insensitive) in addition to the global one; use nil to disable it
+func (cl *APIClient) SetCommandRateLimiter(command string, rl *RL.RateLimiter) *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	key := strings.ToUpper(command)
+	if rl == nil {
+		delete(cl.commandRateLimiters, key)
+	} else {
+		cl.commandRateLimiters[key] = rl
+	}
+	return cl
+}
+
+// GetQueueDepth method to return the number of API requests currently waiting for a rate limiter
+func (cl *APIClient) GetQueueDepth() int {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	depth := 0
+	if cl.rateLimiter != nil {
+		depth += cl.rateLimiter.GetQueueDepth()
+	}
+	for _, rl := range cl.commandRateLimiters {
+		depth += rl.GetQueueDepth()
+	}
+	return depth
+}
+
+// acquire function to wait for the rate limiters applying to the given command.
+// The command rate limiter is waited for first, so a throttled command does not hold
+// a slot of the global one meanwhile.
+// The returned release function has to be called once the request is done.
+func acquire(ctx context.Context, rc *requestConfig, command string) (func(), error) {
+	limiters := []*RL.RateLimiter{}
+	if rl, ok := rc.commandRateLimiters[strings.ToUpper(command)]; ok {
+		limiters = append(limiters, rl)
+	}
+	if rc.rateLimiter != nil {
+		limiters = append(limiters, rc.rateLimiter)
+	}
+	releases := []func(){}
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, rl := range limiters {
+		r, err := rl.Acquire(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
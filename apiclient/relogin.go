@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+	"errors"
+
+	R "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+	SC "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/socketconfig"
+)
+
+// ReloginOptions represents the options for automatically re-login on expired API sessions.
+type ReloginOptions struct {
+	// CredentialsProvider returns the credentials to use for re-login.
+	// If nil, the credentials used for the last successful Login are reused
+	// or the credential provider of the client is consulted.
+	CredentialsProvider func(ctx context.Context) (login string, password string, err error)
+	// OTPProvider returns the one time password to use for re-login of 2FA accounts.
+	OTPProvider func(ctx context.Context) (string, error)
+}
+
+// ReloginError is returned when the automatic re-login after an expired API session failed.
+type ReloginError struct {
+	Response *R.Response
+	Err      error
+}
+
+// Error method to return the error message
+func (e *ReloginError) Error() string {
+	if e.Err != nil {
+		return "automatic re-login failed: " + e.Err.Error()
+	}
+	return "automatic re-login failed: " + e.Response.GetDescription()
+}
+
+// Unwrap method to return the underlying error
+func (e *ReloginError) Unwrap() error {
+	return e.Err
+}
+
+// EnableAutoRelogin method to transparently re-run Login in case a request fails due to an expired
+// session and to replay the original command once. Concurrent re-logins are serialized.
+// The credentials are kept in memory as of the next successful Login, unless a
+// CredentialsProvider is given.
+func (cl *APIClient) EnableAutoRelogin(opts *ReloginOptions) *APIClient {
+	if opts == nil {
+		opts = &ReloginOptions{}
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.autoRelogin = opts
+	return cl
+}
+
+// DisableAutoRelogin method to disable automatic re-login and to forget the kept credentials
+func (cl *APIClient) DisableAutoRelogin() *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.autoRelogin = nil
+	cl.loginConfig = nil
+	return cl
+}
+
+// isSessionExpired function to check if the given API response represents an expired session
+// according to the "expired" template of the given template manager. The original description
+// is matched, so translation rules rewriting it do not get in the way.
+func isSessionExpired(templates *RTM.ResponseTemplateManager, r *R.Response) bool {
+	code, ok := r.GetHash()["CODE"].(string)
+	if !ok {
+		return false
+	}
+	return templates.IsTemplateMatchHash(map[string]interface{}{
+		"CODE":        code,
+		"DESCRIPTION": r.GetOriginalDescription(),
+	}, "expired")
+}
+
+// relogin method to re-login after the given session expired. In case another goroutine already
+// renewed the session in the meantime, nothing is done.
+func (cl *APIClient) relogin(ctx context.Context, expiredSession string) error {
+	cl.reloginMu.Lock()
+	defer cl.reloginMu.Unlock()
+
+	if session := cl.socketConfig.GetSession(); len(session) > 0 && session != expiredSession {
+		return nil
+	}
+
+	cl.mu.RLock()
+	opts := cl.autoRelogin
+	loginConfig := cl.loginConfig
+	cl.mu.RUnlock()
+	if opts == nil {
+		return &ReloginError{Err: errors.New("automatic re-login is disabled")}
+	}
+
+	var sc *SC.SocketConfig
+	if opts.CredentialsProvider != nil {
+		login, password, err := opts.CredentialsProvider(ctx)
+		if err != nil {
+			return &ReloginError{Err: err}
+		}
+		sc = SC.NewSocketConfig().SetLogin(login).SetPassword(password)
+	} else if loginConfig != nil {
+		sc = loginConfig.Clone()
+	} else if provider := cl.socketConfig.GetCredentialProvider(); provider != nil {
+		sc = SC.NewSocketConfig().SetCredentialProvider(provider)
+	} else {
+		return &ReloginError{Err: errors.New("no credentials available")}
+	}
+	sc.SetPersistent()
+	if opts.OTPProvider != nil {
+		otp, err := opts.OTPProvider(ctx)
+		if err != nil {
+			return &ReloginError{Err: err}
+		}
+		sc.SetOTP(otp)
+	}
+
+	rc := cl.snapshot()
+	rc.socketConfig = sc
+	rr := cl.login(ctx, rc)
+	if !rr.IsSuccess() {
+		return &ReloginError{Response: rr}
+	}
+	return nil
+}
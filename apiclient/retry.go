@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
+)
+
+// RetryPolicy represents the configuration for automatically retrying
+// API requests that failed temporarily.
+type RetryPolicy struct {
+	MaxAttempts           int           // MaxAttempts is the maximum number of attempts including the initial one.
+	InitialBackoff        time.Duration // InitialBackoff is the delay before the first retry.
+	MaxBackoff            time.Duration // MaxBackoff caps the delay between two attempts.
+	Multiplier            float64       // Multiplier is applied to the delay after every attempt.
+	Jitter                float64       // Jitter is the random +/- fraction (0..1) applied to every delay.
+	RetryableCodes        []int         // RetryableCodes lists the API response codes to retry; all temporary error codes (4xx) if empty.
+	RetryableHTTPStatuses []int         // RetryableHTTPStatuses lists the HTTP status codes to retry.
+	IdempotentCommands    []string      // IdempotentCommands lists the commands allowed to be retried; a trailing `*` matches any suffix.
+}
+
+// NewRetryPolicy creates a new instance of RetryPolicy with default values.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:           3,
+		InitialBackoff:        500 * time.Millisecond,
+		MaxBackoff:            10 * time.Second,
+		Multiplier:            2,
+		Jitter:                0.2,
+		RetryableCodes:        []int{},
+		RetryableHTTPStatuses: []int{429, 502, 503, 504},
+		IdempotentCommands:    []string{"Status*", "Query*", "Check*"},
+	}
+}
+
+// IsIdempotentCommand method to check if the given command is allowed to be retried (case-insensitive)
+func (p *RetryPolicy) IsIdempotentCommand(command string) bool {
+	command = strings.ToLower(command)
+	if command == "" {
+		return false
+	}
+	for _, pattern := range p.IdempotentCommands {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(command, prefix) {
+				return true
+			}
+		} else if command == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryableError method to check if the given request error is worth another attempt.
+// Transport errors include attempts running into the socket timeout; requests whose
+// context is done are never retried, independent of the error.
+func (p *RetryPolicy) IsRetryableError(err error) bool {
+	var transportErr *TransportError
+	var statusErr *HTTPStatusError
+	var apiErr *APIError
+	var parseErrs rp.ParseErrors
+	switch {
+	case errors.As(err, &transportErr), errors.As(err, &parseErrs):
+		// also strictly parsed responses found to be malformed, e.g. truncated bodies
+		return true
+	case errors.As(err, &statusErr):
+		return slices.Contains(p.RetryableHTTPStatuses, statusErr.StatusCode)
+	case errors.As(err, &apiErr):
+		if len(p.RetryableCodes) == 0 {
+			return apiErr.IsTmpError()
+		}
+		return slices.Contains(p.RetryableCodes, apiErr.Code)
+	}
+	return false
+}
+
+// Backoff method to return the delay to wait before the given attempt (starting with 2 for the first retry)
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-2))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 - p.Jitter + 2*p.Jitter*rand.Float64() //nolint:gosec
+	}
+	return time.Duration(delay)
+}
+
+// SetRetryPolicy method to set the policy used to retry temporarily failed requests.
+// Retrying is disabled by default; use nil to disable it again.
+func (cl *APIClient) SetRetryPolicy(policy *RetryPolicy) *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.retryPolicy = policy
+	return cl
+}
+
+// GetRetryPolicy method to get the policy used to retry temporarily failed requests
+func (cl *APIClient) GetRetryPolicy() *RetryPolicy {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.retryPolicy
+}
+
+// wait method to sleep for the given duration unless the context is done before
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrPinMismatch is returned when none of the certificates presented by the API endpoint
+// matches a pinned public key
+var ErrPinMismatch = errors.New("no certificate matches the pinned public keys")
+
+// SetRootCAs method to set the pool of root certificate authorities to verify the API
+// endpoint against, e.g. for TLS-intercepting gateways; use nil to reset to the system pool.
+// ErrTransportNotComposable is returned in case the configured transport does not support
+// TLS options; the same applies to the other TLS setters.
+func (cl *APIClient) SetRootCAs(pool *x509.CertPool) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.tlsRootCAs = pool
+	return cl.updateHTTPClient()
+}
+
+// AddClientCertificate method to add a client certificate to present for mutual TLS
+func (cl *APIClient) AddClientCertificate(cert tls.Certificate) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.tlsCertificates = append(cl.tlsCertificates, cert)
+	return cl.updateHTTPClient()
+}
+
+// LoadClientCertificate method to load a PEM encoded client certificate/key pair from the
+// given files and to add it for mutual TLS
+func (cl *APIClient) LoadClientCertificate(certFile string, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	return cl.AddClientCertificate(cert)
+}
+
+// ResetClientCertificates method to remove all client certificates
+func (cl *APIClient) ResetClientCertificates() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.tlsCertificates = nil
+	return cl.updateHTTPClient()
+}
+
+// SetMinTLSVersion method to set the minimum TLS version to accept, e.g. tls.VersionTLS13;
+// use 0 to reset to the default
+func (cl *APIClient) SetMinTLSVersion(version uint16) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.tlsMinVersion = version
+	return cl.updateHTTPClient()
+}
+
+// SetPinnedPublicKeys method to pin the public keys accepted for the API endpoint. Provide
+// the base64 encoded SHA-256 hashes of the DER encoded SubjectPublicKeyInfo (as used for
+// HPKP); a connection is accepted if any certificate of the verified chain matches.
+// Call it without arguments to disable pinning.
+func (cl *APIClient) SetPinnedPublicKeys(pins ...string) error {
+	hashes := make([][]byte, 0, len(pins))
+	for _, pin := range pins {
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil {
+			return fmt.Errorf("invalid public key pin %q: %w", pin, err)
+		}
+		if len(hash) != sha256.Size {
+			return fmt.Errorf("invalid public key pin %q: expected SHA-256 hash", pin)
+		}
+		hashes = append(hashes, hash)
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.tlsPins = hashes
+	return cl.updateHTTPClient()
+}
+
+// PublicKeyPin function to return the pin of the public key of the given certificate
+// to be used with SetPinnedPublicKeys
+func PublicKeyPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// hasTLSOptions method to check if any TLS option is configured;
+// expects the caller to hold the lock
+func (cl *APIClient) hasTLSOptions() bool {
+	return cl.tlsRootCAs != nil || len(cl.tlsCertificates) > 0 || cl.tlsMinVersion > 0 || len(cl.tlsPins) > 0
+}
+
+// newTLSConfig method to compose the given TLS configuration with the configured TLS options;
+// expects the caller to hold the lock
+func (cl *APIClient) newTLSConfig(base *tls.Config) *tls.Config {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	if cl.tlsRootCAs != nil {
+		cfg.RootCAs = cl.tlsRootCAs
+	}
+	if len(cl.tlsCertificates) > 0 {
+		cfg.Certificates = append(cfg.Certificates, cl.tlsCertificates...)
+	}
+	if cl.tlsMinVersion > 0 {
+		cfg.MinVersion = cl.tlsMinVersion
+	}
+	if len(cl.tlsPins) > 0 {
+		pins := cl.tlsPins
+		next := cfg.VerifyConnection
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if next != nil {
+				if err := next(cs); err != nil {
+					return err
+				}
+			}
+			return verifyPins(cs, pins)
+		}
+	}
+	return cfg
+}
+
+// verifyPins function to check that any certificate of the verified chains matches a pin
+func verifyPins(cs tls.ConnectionState, pins [][]byte) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pin := range pins {
+				if bytes.Equal(hash[:], pin) {
+					return nil
+				}
+			}
+		}
+	}
+	return ErrPinMismatch
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SetSocketTimeout method to set the timeout for API communication; use 0 to disable it.
+// The timeout is applied per request attempt, also when using a custom HTTP client.
+func (cl *APIClient) SetSocketTimeout(timeout time.Duration) *APIClient {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.socketTimeout = timeout
+	return cl
+}
+
+// GetSocketTimeout method to get the timeout for API communication
+func (cl *APIClient) GetSocketTimeout() time.Duration {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.socketTimeout
+}
+
+// ErrTransportNotComposable is returned when proxy or TLS settings are configured along with a
+// custom transport they cannot be applied to, i.e. an http.RoundTripper other than *http.Transport
+var ErrTransportNotComposable = errors.New("proxy and TLS settings cannot be applied to the custom transport")
+
+// SetHTTPClient method to set a custom HTTP client to use for API communication; use nil
+// to reset to the built-in one. The given client is left untouched: a copy of it is used
+// with the proxy and TLS settings composed into its transport (see SetTransport).
+// ErrTransportNotComposable is returned if these settings cannot be applied; requests fail
+// with it until the configuration is fixed.
+func (cl *APIClient) SetHTTPClient(client *http.Client) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.httpClient = client
+	return cl.updateHTTPClient()
+}
+
+// SetTransport method to set a custom transport to use for API communication; use nil to
+// reset to the one of the custom HTTP client or to the default one. An *http.Transport is
+// cloned and the proxy and TLS settings are applied to the clone. Any other http.RoundTripper
+// is used as is; ErrTransportNotComposable is returned if proxy or TLS settings are
+// configured, and requests fail with it until the configuration is fixed.
+func (cl *APIClient) SetTransport(transport http.RoundTripper) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.transport = transport
+	return cl.updateHTTPClient()
+}
+
+// updateHTTPClient method to rebuild the HTTP client out of the current client configuration
+// and to return the error rendering the configuration unusable, if any;
+// expects the caller to hold the lock
+func (cl *APIClient) updateHTTPClient() error {
+	cl.client, cl.clientErr = cl.newHTTPClient()
+	return cl.clientErr
+}
+
+// newHTTPClient method to build the HTTP client out of the current client configuration;
+// expects the caller to hold the lock
+func (cl *APIClient) newHTTPClient() (*http.Client, error) {
+	client := &http.Client{}
+	base := cl.transport
+	if cl.httpClient != nil {
+		// work on a copy to leave the given client untouched
+		c := *cl.httpClient
+		client = &c
+		if base == nil {
+			base = c.Transport
+		}
+	}
+	transport, err := cl.newTransport(base)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+// newTransport method to compose the given transport with the proxy and TLS settings;
+// expects the caller to hold the lock
+func (cl *APIClient) newTransport(base http.RoundTripper) (http.RoundTripper, error) {
+	hasProxy := cl.proxyURL != nil
+	hasTLS := cl.hasTLSOptions()
+	if !hasProxy && !hasTLS {
+		// nil represents http.DefaultTransport, honouring the proxy environment variables
+		return base, nil
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrTransportNotComposable, base)
+	}
+	t = t.Clone()
+	if hasProxy {
+		t.Proxy = http.ProxyURL(cl.proxyURL)
+	}
+	if hasTLS {
+		t.TLSClientConfig = cl.newTLSConfig(t.TLSClientConfig)
+	}
+	return t, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+// Package commands provides typed builders for common registry commands that marshal
+// into the flattened command format used for API communication, e.g.
+//
+//	cmd, err := commands.ToMap(&commands.AddDomain{
+//	    Domain:       "example.com",
+//	    Period:       1,
+//	    OwnerContact: "P-ABC1",
+//	    Nameservers:  []string{"ns1.example.net", "ns2.example.net"},
+//	})
+//
+// Struct fields are mapped to API parameters by the `api` struct tag. Fields of type
+// []string are expanded into indexed parameters (NAMESERVER0, NAMESERVER1, ...), zero
+// values are omitted (use pointers to send them explicitly) and fields tagged as
+// `required` are validated before marshalling.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Command represents a typed API command
+type Command interface {
+	// CommandName returns the name of the API command, e.g. "AddDomain"
+	CommandName() string
+}
+
+// ValidationError is returned when required parameters of a command are missing
+type ValidationError struct {
+	Command string
+	Missing []string
+}
+
+// Error method to return the error message
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid command %s: missing required parameters %s", e.Command, strings.Join(e.Missing, ", "))
+}
+
+// Marshal function to return the flattened API command for the given typed command
+func Marshal(cmd Command) (map[string]string, error) {
+	v := reflect.ValueOf(cmd)
+	if !v.IsValid() {
+		return nil, errors.New("invalid command: nil")
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("invalid command: nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid command: unsupported type %s", v.Type())
+	}
+	result := map[string]string{
+		"COMMAND": cmd.CommandName(),
+	}
+	missing := []string{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, ok := parseTag(field)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if opts == "extra" {
+			if err := marshalExtra(fv, result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		set, err := marshalField(name, fv, result, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command %s: %w", cmd.CommandName(), err)
+		}
+		if !set && opts == "required" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &ValidationError{Command: cmd.CommandName(), Missing: missing}
+	}
+	return result, nil
+}
+
+// ToMap function to return the flattened API command for the given typed command in the
+// format accepted by apiclient.APIClient.Request
+func ToMap(cmd Command) (map[string]interface{}, error) {
+	m, err := Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		result[key] = val
+	}
+	return result, nil
+}
+
+// parseTag function to return the parameter name and option of the given struct field
+func parseTag(field reflect.StructField) (string, string, bool) {
+	tag, ok := field.Tag.Lookup("api")
+	if !ok || tag == "-" || !field.IsExported() {
+		return "", "", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return strings.ToUpper(name), opts, true
+}
+
+// marshalField function to add the given field value to the command and to return
+// if it has been set. Zero values are omitted unless provided by pointer.
+func marshalField(name string, fv reflect.Value, cmd map[string]string, explicit bool) (bool, error) {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return false, nil
+		}
+		return marshalField(name, fv.Elem(), cmd, true)
+	case reflect.String:
+		if fv.Len() == 0 {
+			return false, nil
+		}
+		cmd[name] = sanitize(fv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Int() == 0 && !explicit {
+			return false, nil
+		}
+		cmd[name] = strconv.FormatInt(fv.Int(), 10)
+	case reflect.Bool:
+		if !fv.Bool() && !explicit {
+			return false, nil
+		}
+		if fv.Bool() {
+			cmd[name] = "1"
+		} else {
+			cmd[name] = "0"
+		}
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return false, fmt.Errorf("unsupported type %s of parameter %s", fv.Type(), name)
+		}
+		for idx := 0; idx < fv.Len(); idx++ {
+			cmd[name+strconv.Itoa(idx)] = sanitize(fv.Index(idx).String())
+		}
+		return fv.Len() > 0, nil
+	default:
+		return false, fmt.Errorf("unsupported type %s of parameter %s", fv.Type(), name)
+	}
+	return true, nil
+}
+
+// marshalExtra function to add the given additional parameters to the command
+// without overriding typed ones
+func marshalExtra(fv reflect.Value, cmd map[string]string) error {
+	if fv.Kind() != reflect.Map || fv.Type().Key().Kind() != reflect.String || fv.Type().Elem().Kind() != reflect.String {
+		return fmt.Errorf("unsupported type %s for additional parameters", fv.Type())
+	}
+	keys := make([]string, 0, fv.Len())
+	for _, key := range fv.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		if _, exists := cmd[name]; exists {
+			continue
+		}
+		cmd[name] = sanitize(fv.MapIndex(reflect.ValueOf(key)).String())
+	}
+	return nil
+}
+
+// sanitize function to remove line breaks from a parameter value
+func sanitize(val string) string {
+	val = strings.ReplaceAll(val, "\r", "")
+	return strings.ReplaceAll(val, "\n", "")
+}
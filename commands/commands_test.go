@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalAddDomain(t *testing.T) {
+	transferLock := false
+	cmd, err := Marshal(&AddDomain{
+		Domain:       "example.com",
+		Period:       2,
+		OwnerContact: "P-ABC1",
+		Nameservers:  []string{"ns1.example.net", "ns2.example.net\r\n"},
+		TransferLock: &transferLock,
+		Params:       map[string]string{"x-accept-tac": "1", "domain": "ignored.com"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"COMMAND":       "AddDomain",
+		"DOMAIN":        "example.com",
+		"PERIOD":        "2",
+		"OWNERCONTACT0": "P-ABC1",
+		"NAMESERVER0":   "ns1.example.net",
+		"NAMESERVER1":   "ns2.example.net",
+		"TRANSFERLOCK":  "0",
+		"X-ACCEPT-TAC":  "1",
+	}, cmd)
+}
+
+func TestMarshalCheckDomains(t *testing.T) {
+	cmd, err := ToMap(&CheckDomains{Domains: []string{"example.com", "example.net"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"COMMAND": "CheckDomains",
+		"DOMAIN0": "example.com",
+		"DOMAIN1": "example.net",
+	}, cmd)
+}
+
+func TestMarshalRequired(t *testing.T) {
+	testCases := []struct {
+		cmd     Command
+		missing []string
+	}{
+		{&CheckDomains{}, []string{"DOMAIN"}},
+		{&AddDomain{Period: 1}, []string{"DOMAIN"}},
+		{&ModifyDomain{}, []string{"DOMAIN"}},
+		{&RenewDomain{}, []string{"DOMAIN", "PERIOD"}},
+		{&TransferDomain{Action: "REQUEST"}, []string{"DOMAIN"}},
+		{&StatusDomain{}, []string{"DOMAIN"}},
+		{&DeleteDomain{}, []string{"DOMAIN"}},
+	}
+	for _, tc := range testCases {
+		_, err := Marshal(tc.cmd)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("TestMarshalRequired: Expected validation error for %s, got %v", tc.cmd.CommandName(), err)
+			continue
+		}
+		assert.Equal(t, tc.cmd.CommandName(), verr.Command)
+		assert.Equal(t, tc.missing, verr.Missing)
+	}
+}
+
+func TestMarshalCommandNames(t *testing.T) {
+	testCases := map[string]Command{
+		"CheckDomains":   &CheckDomains{Domains: []string{"example.com"}},
+		"AddDomain":      &AddDomain{Domain: "example.com"},
+		"ModifyDomain":   &ModifyDomain{Domain: "example.com"},
+		"RenewDomain":    &RenewDomain{Domain: "example.com", Period: 1, Expiration: 2030},
+		"TransferDomain": &TransferDomain{Domain: "example.com", Action: "REQUEST", Auth: "secret"},
+		"StatusDomain":   &StatusDomain{Domain: "example.com"},
+		"DeleteDomain":   &DeleteDomain{Domain: "example.com"},
+	}
+	for name, c := range testCases {
+		cmd, err := Marshal(c)
+		assert.NoError(t, err)
+		assert.Equal(t, name, cmd["COMMAND"])
+		assert.Equal(t, "example.com", cmd["DOMAIN"]+cmd["DOMAIN0"])
+	}
+}
+
+type unsupportedCommand struct {
+	Values []int `api:"VALUE"`
+}
+
+func (c *unsupportedCommand) CommandName() string { return "Unsupported" }
+
+func TestMarshalUnsupported(t *testing.T) {
+	_, err := Marshal(&unsupportedCommand{Values: []int{1}})
+	if err == nil {
+		t.Error("TestMarshalUnsupported: Expected error for unsupported field type.")
+	}
+	_, err = Marshal((*StatusDomain)(nil))
+	if err == nil {
+		t.Error("TestMarshalUnsupported: Expected error for nil command.")
+	}
+	_, err = Marshal(nil)
+	if err == nil {
+		t.Error("TestMarshalUnsupported: Expected error for nil interface.")
+	}
+}
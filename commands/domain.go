@@ -0,0 +1,98 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package commands
+
+// CheckDomains represents the command to check the availability of domains
+type CheckDomains struct {
+	Domains []string          `api:"DOMAIN,required"`
+	Params  map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *CheckDomains) CommandName() string { return "CheckDomains" }
+
+// AddDomain represents the command to register a domain
+type AddDomain struct {
+	Domain         string            `api:"DOMAIN,required"`
+	Period         int               `api:"PERIOD"`
+	OwnerContact   string            `api:"OWNERCONTACT0"`
+	AdminContact   string            `api:"ADMINCONTACT0"`
+	TechContact    string            `api:"TECHCONTACT0"`
+	BillingContact string            `api:"BILLINGCONTACT0"`
+	Nameservers    []string          `api:"NAMESERVER"`
+	Auth           string            `api:"AUTH"`
+	TransferLock   *bool             `api:"TRANSFERLOCK"`
+	Params         map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *AddDomain) CommandName() string { return "AddDomain" }
+
+// ModifyDomain represents the command to update a domain
+type ModifyDomain struct {
+	Domain         string            `api:"DOMAIN,required"`
+	OwnerContact   string            `api:"OWNERCONTACT0"`
+	AdminContact   string            `api:"ADMINCONTACT0"`
+	TechContact    string            `api:"TECHCONTACT0"`
+	BillingContact string            `api:"BILLINGCONTACT0"`
+	Nameservers    []string          `api:"NAMESERVER"`
+	Auth           string            `api:"AUTH"`
+	TransferLock   *bool             `api:"TRANSFERLOCK"`
+	RenewalMode    string            `api:"RENEWALMODE"`
+	TransferMode   string            `api:"TRANSFERMODE"`
+	Params         map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *ModifyDomain) CommandName() string { return "ModifyDomain" }
+
+// RenewDomain represents the command to explicitly renew a domain
+type RenewDomain struct {
+	Domain string `api:"DOMAIN,required"`
+	Period int    `api:"PERIOD,required"`
+	// Expiration represents the current expiration year of the domain
+	Expiration int               `api:"EXPIRATION"`
+	Params     map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *RenewDomain) CommandName() string { return "RenewDomain" }
+
+// TransferDomain represents the command to request or to handle a domain transfer
+type TransferDomain struct {
+	Domain string `api:"DOMAIN,required"`
+	// Action represents the transfer action, e.g. REQUEST, APPROVE, DENY or CANCEL
+	Action         string            `api:"ACTION"`
+	Auth           string            `api:"AUTH"`
+	Period         int               `api:"PERIOD"`
+	OwnerContact   string            `api:"OWNERCONTACT0"`
+	AdminContact   string            `api:"ADMINCONTACT0"`
+	TechContact    string            `api:"TECHCONTACT0"`
+	BillingContact string            `api:"BILLINGCONTACT0"`
+	Nameservers    []string          `api:"NAMESERVER"`
+	Params         map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *TransferDomain) CommandName() string { return "TransferDomain" }
+
+// StatusDomain represents the command to request the status of a domain
+type StatusDomain struct {
+	Domain string            `api:"DOMAIN,required"`
+	Params map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *StatusDomain) CommandName() string { return "StatusDomain" }
+
+// DeleteDomain represents the command to delete a domain
+type DeleteDomain struct {
+	Domain string            `api:"DOMAIN,required"`
+	Params map[string]string `api:",extra"`
+}
+
+// CommandName method to return the name of the API command
+func (c *DeleteDomain) CommandName() string { return "DeleteDomain" }
@@ -0,0 +1,237 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+// Package credentials provides built-in implementations of socketconfig.CredentialProvider
+// to read API credentials from environment variables, secret files or netrc-style files
+// at request time, so that rotated passwords are picked up without restarting
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvProvider is a credential provider reading the credentials from environment variables
+type EnvProvider struct {
+	loginVar    string
+	passwordVar string
+}
+
+// NewEnvProvider represents the constructor for struct EnvProvider.
+// Provide the names of the environment variables holding login and password.
+func NewEnvProvider(loginVar string, passwordVar string) *EnvProvider {
+	return &EnvProvider{
+		loginVar:    loginVar,
+		passwordVar: passwordVar,
+	}
+}
+
+// GetCredentials method to return the credentials read from the environment
+func (p *EnvProvider) GetCredentials(_ context.Context, _ string) (string, string, error) {
+	login, ok := os.LookupEnv(p.loginVar)
+	if !ok {
+		return "", "", fmt.Errorf("environment variable %s not set", p.loginVar)
+	}
+	password, ok := os.LookupEnv(p.passwordVar)
+	if !ok {
+		return "", "", fmt.Errorf("environment variable %s not set", p.passwordVar)
+	}
+	return login, password, nil
+}
+
+// mtimeGranularity represents the coarsest modification time resolution of common file
+// systems. Changes within it may keep modification time and size, e.g. a rotated
+// secret of the same length, so files modified that recently are always re-read.
+const mtimeGranularity = 2 * time.Second
+
+// cachedFile represents a file whose contents are re-read only when it changed on disk
+type cachedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	readAt  time.Time
+	data    []byte
+}
+
+// read method to return the file contents, reloading them if the file changed
+func (f *cachedFile) read() ([]byte, error) {
+	now := time.Now()
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if f.data != nil && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size && f.readAt.Sub(f.modTime) >= mtimeGranularity {
+		return f.data, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	f.data = data
+	f.modTime = fi.ModTime()
+	f.size = fi.Size()
+	f.readAt = now
+	return data, nil
+}
+
+// FileProvider is a credential provider reading the credentials from secret files as
+// mounted by Docker or Kubernetes. Files are reloaded when they change on disk.
+type FileProvider struct {
+	mu       sync.Mutex
+	login    *cachedFile
+	password *cachedFile
+}
+
+// NewFileProvider represents the constructor for struct FileProvider.
+// Provide the paths of the files holding login and password.
+func NewFileProvider(loginFile string, passwordFile string) *FileProvider {
+	return &FileProvider{
+		login:    &cachedFile{path: loginFile},
+		password: &cachedFile{path: passwordFile},
+	}
+}
+
+// GetCredentials method to return the credentials read from the secret files
+func (p *FileProvider) GetCredentials(_ context.Context, _ string) (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	login, err := p.login.read()
+	if err != nil {
+		return "", "", err
+	}
+	password, err := p.password.read()
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(string(login)), strings.TrimRight(string(password), "\r\n"), nil
+}
+
+// NetrcProvider is a credential provider reading the credentials from a netrc-style file
+// where the machine entry is matched against the host of the API url in use, falling back
+// to the default entry. The file is reloaded when it changes on disk.
+type NetrcProvider struct {
+	mu   sync.Mutex
+	file *cachedFile
+}
+
+// NewNetrcProvider represents the constructor for struct NetrcProvider.
+func NewNetrcProvider(path string) *NetrcProvider {
+	return &NetrcProvider{
+		file: &cachedFile{path: path},
+	}
+}
+
+// GetCredentials method to return the credentials of the entry matching the given API url
+func (p *NetrcProvider) GetCredentials(_ context.Context, apiURL string) (string, string, error) {
+	p.mu.Lock()
+	data, err := p.file.read()
+	p.mu.Unlock()
+	if err != nil {
+		return "", "", err
+	}
+	host := apiURL
+	if u, err := url.Parse(apiURL); err == nil && len(u.Hostname()) > 0 {
+		host = u.Hostname()
+	}
+	login, password, found := lookupNetrc(data, host)
+	if !found {
+		return "", "", fmt.Errorf("no netrc entry found for %s", host)
+	}
+	return login, password, nil
+}
+
+// lookupNetrc function to return the credentials of the machine entry matching host
+// or of the default entry
+func lookupNetrc(data []byte, host string) (string, string, bool) {
+	type entry struct {
+		login    string
+		password string
+	}
+	var (
+		current  *entry
+		matched  *entry
+		fallback *entry
+	)
+	tokens := netrcTokens(data)
+	next := func() (string, bool) {
+		if len(tokens) == 0 {
+			return "", false
+		}
+		token := tokens[0]
+		tokens = tokens[1:]
+		return token, true
+	}
+	for token, ok := next(); ok; token, ok = next() {
+		switch token {
+		case "machine":
+			current = nil
+			if name, ok := next(); ok && strings.EqualFold(name, host) && matched == nil {
+				matched = &entry{}
+				current = matched
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = &entry{}
+				current = fallback
+			}
+		case "login":
+			if login, ok := next(); ok && current != nil {
+				current.login = login
+			}
+		case "password":
+			if password, ok := next(); ok && current != nil {
+				current.password = password
+			}
+		case "account":
+			next()
+		}
+	}
+	if matched == nil {
+		matched = fallback
+	}
+	if matched == nil {
+		return "", "", false
+	}
+	return matched.login, matched.password, true
+}
+
+// netrcTokens function to split the given netrc data into its tokens, skipping macro
+// definitions (macdef) whose bodies end at the next empty line
+func netrcTokens(data []byte) []string {
+	tokens := []string{}
+	inMacro := false
+	expectValue := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			inMacro = len(strings.TrimSpace(line)) > 0
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if !expectValue && field == "macdef" {
+				// the macro name is the rest of the line, its body starts on the next one
+				inMacro = true
+				break
+			}
+			tokens = append(tokens, field)
+			switch {
+			case expectValue:
+				expectValue = false
+			case field == "machine", field == "login", field == "password", field == "account":
+				expectValue = true
+			}
+		}
+	}
+	return tokens
+}
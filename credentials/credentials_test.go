@@ -0,0 +1,98 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("TEST_API_LOGIN", "myaccountid")
+	t.Setenv("TEST_API_PASSWORD", "mypassword")
+	login, password, err := NewEnvProvider("TEST_API_LOGIN", "TEST_API_PASSWORD").GetCredentials(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "myaccountid", login)
+	assert.Equal(t, "mypassword", password)
+
+	_, _, err = NewEnvProvider("TEST_API_LOGIN", "TEST_API_UNSET").GetCredentials(context.Background(), "")
+	if err == nil {
+		t.Error("TestEnvProvider: Expected error for unset environment variable.")
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	loginFile := filepath.Join(dir, "login")
+	passwordFile := filepath.Join(dir, "password")
+	assert.NoError(t, os.WriteFile(loginFile, []byte("myaccountid\n"), 0o600))
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("mypassword\n"), 0o600))
+
+	p := NewFileProvider(loginFile, passwordFile)
+	login, password, err := p.GetCredentials(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "myaccountid", login)
+	assert.Equal(t, "mypassword", password)
+
+	// rotated secret is picked up
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("myrotatedpassword\n"), 0o600))
+	_, password, err = p.GetCredentials(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "myrotatedpassword", password)
+
+	// also when rotated within the modification time granularity keeping the size
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("myrotatedpassword2\n"), 0o600))
+	_, password, err = p.GetCredentials(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "myrotatedpassword2", password)
+	fi, err := os.Stat(passwordFile)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("myrotatedpassword3\n"), 0o600))
+	assert.NoError(t, os.Chtimes(passwordFile, fi.ModTime(), fi.ModTime()))
+	_, password, err = p.GetCredentials(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "myrotatedpassword3", password)
+
+	assert.NoError(t, os.Remove(loginFile))
+	_, _, err = p.GetCredentials(context.Background(), "")
+	if err == nil {
+		t.Error("TestFileProvider: Expected error for missing secret file.")
+	}
+}
+
+func TestNetrcProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netrc")
+	netrc := "machine api.rrpproxy.net\n  login liveaccount\n  password livepassword\n\n" +
+		"macdef init\n  machine api-ote.rrpproxy.net login macroaccount password macropassword\n\n" +
+		"machine api-ote.rrpproxy.net login testaccount account ignored password testpassword\n" +
+		"default login defaultaccount password defaultpassword\n"
+	assert.NoError(t, os.WriteFile(path, []byte(netrc), 0o600))
+
+	p := NewNetrcProvider(path)
+	login, password, err := p.GetCredentials(context.Background(), "https://api.rrpproxy.net/api/call.cgi")
+	assert.NoError(t, err)
+	assert.Equal(t, "liveaccount", login)
+	assert.Equal(t, "livepassword", password)
+
+	login, password, err = p.GetCredentials(context.Background(), "https://api-ote.rrpproxy.net/api/call.cgi")
+	assert.NoError(t, err)
+	assert.Equal(t, "testaccount", login)
+	assert.Equal(t, "testpassword", password)
+
+	login, password, err = p.GetCredentials(context.Background(), "https://localhost/api/call.cgi")
+	assert.NoError(t, err)
+	assert.Equal(t, "defaultaccount", login)
+	assert.Equal(t, "defaultpassword", password)
+
+	assert.NoError(t, os.WriteFile(path, []byte("machine api.rrpproxy.net login liveaccount password rotatedpassword\n"), 0o600))
+	_, password, err = p.GetCredentials(context.Background(), "https://api.rrpproxy.net/api/call.cgi")
+	assert.NoError(t, err)
+	assert.Equal(t, "rotatedpassword", password)
+
+	_, _, err = p.GetCredentials(context.Background(), "https://localhost/api/call.cgi")
+	if err == nil {
+		t.Error("TestNetrcProvider: Expected error for missing netrc entry.")
+	}
+}
@@ -33,4 +33,5 @@ func (c *CustomLogger) Log(post string, r *R.Response, errormsg ...string) {
 		fmt.Printf("HTTP communication failed: %s\n", errormsg)
 	}
 	fmt.Println(r.GetPlain())
+	L.LogTranslation(r)
 }
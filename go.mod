@@ -6,10 +6,10 @@ require (
 	github.com/stretchr/testify v1.11.1 // using this version to make it compatible with dnscontrol
 	golang.org/x/net v0.47.0 // using this version to make it compatible with dnscontrol
 	golang.org/x/text v0.31.0 // using this version to make it compatible with dnscontrol
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -33,4 +33,20 @@ func (c *Logger) Log(post string, r *R.Response, errormsg ...string) {
 		fmt.Printf("HTTP communication failed: %s\n", errormsg)
 	}
 	fmt.Println(r.GetPlain())
+	LogTranslation(r)
+}
+
+// LogTranslation function to ouput/log the original API response of a translated response
+func LogTranslation(r *R.Response) {
+	if !r.IsTranslated() {
+		return
+	}
+	rule := r.GetTranslationRule()
+	if len(rule) == 0 {
+		rule = "none"
+	}
+	fmt.Printf("Translated (rule: %s), original description: %s\n", rule, r.GetOriginalDescription())
+	if plain := r.GetOriginalPlain(); len(plain) > 0 {
+		fmt.Println(plain)
+	}
 }
@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+// Package ratelimiter provides a token bucket rate limiter combined with a
+// concurrency cap to throttle API communication on client side
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter is a struct representing a token bucket rate limiter with an optional
+// cap of requests in flight. It is safe for concurrent use by multiple goroutines.
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    int
+	tokens   float64
+	last     time.Time
+	slots    chan struct{}
+	waiting  atomic.Int64
+	inFlight atomic.Int64
+}
+
+// NewRateLimiter represents the constructor for struct RateLimiter.
+// rate is the number of requests allowed per second (<= 0 for unlimited), burst the
+// bucket size (at least 1) and maxInFlight the number of concurrent requests allowed
+// (<= 0 for unlimited).
+func NewRateLimiter(rate float64, burst int, maxInFlight int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	rl := &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	if maxInFlight > 0 {
+		rl.slots = make(chan struct{}, maxInFlight)
+	}
+	return rl
+}
+
+// Acquire method to wait for a free slot and a token. The returned release function
+// has to be called once the request is done. An error is returned if the given
+// context is done before.
+func (rl *RateLimiter) Acquire(ctx context.Context) (func(), error) {
+	rl.waiting.Add(1)
+	defer rl.waiting.Add(-1)
+
+	if rl.slots != nil {
+		select {
+		case rl.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err := rl.waitForToken(ctx); err != nil {
+		rl.releaseSlot()
+		return nil, err
+	}
+	rl.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			rl.inFlight.Add(-1)
+			rl.releaseSlot()
+		})
+	}, nil
+}
+
+// GetQueueDepth method to return the number of requests currently waiting
+func (rl *RateLimiter) GetQueueDepth() int {
+	return int(rl.waiting.Load())
+}
+
+// GetInFlight method to return the number of requests currently in flight
+func (rl *RateLimiter) GetInFlight() int {
+	return int(rl.inFlight.Load())
+}
+
+// releaseSlot method to free a concurrency slot
+func (rl *RateLimiter) releaseSlot() {
+	if rl.slots != nil {
+		<-rl.slots
+	}
+}
+
+// waitForToken method to reserve a token and to wait until it becomes available
+func (rl *RateLimiter) waitForToken(ctx context.Context) error {
+	delay := rl.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.cancelReservation()
+		return ctx.Err()
+	}
+}
+
+// reserve method to take a token out of the bucket and to return the delay
+// until that token is covered by the refill rate
+func (rl *RateLimiter) reserve() time.Duration {
+	if rl.rate <= 0 {
+		return 0
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > float64(rl.burst) {
+		rl.tokens = float64(rl.burst)
+	}
+	rl.last = now
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+}
+
+// cancelReservation method to return a reserved token to the bucket
+func (rl *RateLimiter) cancelReservation() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.tokens++
+}
@@ -0,0 +1,87 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireBurst(t *testing.T) {
+	rl := NewRateLimiter(1, 3, 0)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := rl.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("TestAcquireBurst: Expected not to run into error: %v", err)
+		}
+		release()
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("TestAcquireBurst: Expected burst to be served without waiting.")
+	}
+}
+
+func TestAcquireRate(t *testing.T) {
+	rl := NewRateLimiter(20, 1, 0)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := rl.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("TestAcquireRate: Expected not to run into error: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("TestAcquireRate: Expected requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestAcquireContextCancelled(t *testing.T) {
+	rl := NewRateLimiter(0.1, 1, 0)
+	release, err := rl.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("TestAcquireContextCancelled: Expected not to run into error: %v", err)
+	}
+	release()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rl.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("TestAcquireContextCancelled: Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	rl := NewRateLimiter(0, 1, 2)
+	first, _ := rl.Acquire(context.Background())
+	second, _ := rl.Acquire(context.Background())
+	if rl.GetInFlight() != 2 {
+		t.Errorf("TestMaxInFlight: Expected 2 requests in flight, got %d", rl.GetInFlight())
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		release, err := rl.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("TestMaxInFlight: Expected not to run into error: %v", err)
+			return
+		}
+		release()
+	}()
+	deadline := time.Now().Add(time.Second)
+	for rl.GetQueueDepth() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if rl.GetQueueDepth() != 1 {
+		t.Errorf("TestMaxInFlight: Expected queue depth 1, got %d", rl.GetQueueDepth())
+	}
+	first()
+	first()
+	wg.Wait()
+	second()
+	if rl.GetInFlight() != 0 || rl.GetQueueDepth() != 0 {
+		t.Error("TestMaxInFlight: Expected no requests in flight or waiting.")
+	}
+}
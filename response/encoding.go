@@ -0,0 +1,160 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+
+	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
+)
+
+// jsonResponse represents the stable serialization schema of a Response
+type jsonResponse struct {
+	Code        int                 `json:"code" yaml:"code"`
+	Description string              `json:"description" yaml:"description"`
+	Runtime     float64             `json:"runtime" yaml:"runtime"`
+	Queuetime   float64             `json:"queuetime" yaml:"queuetime"`
+	Command     map[string]string   `json:"command" yaml:"command"`
+	Columns     []string            `json:"columns" yaml:"columns"`
+	Records     []map[string]string `json:"records" yaml:"records"`
+	Pagination  *jsonPagination     `json:"pagination,omitempty" yaml:"pagination,omitempty"`
+}
+
+// jsonPagination represents the serialization schema of the pagination data of a Response
+type jsonPagination struct {
+	Count        int `json:"count" yaml:"count"`
+	CurrentPage  int `json:"currentpage" yaml:"currentpage"`
+	First        int `json:"first" yaml:"first"`
+	Last         int `json:"last" yaml:"last"`
+	Limit        int `json:"limit" yaml:"limit"`
+	NextPage     int `json:"nextpage" yaml:"nextpage"`
+	Pages        int `json:"pages" yaml:"pages"`
+	PreviousPage int `json:"previouspage" yaml:"previouspage"`
+	Total        int `json:"total" yaml:"total"`
+}
+
+// serializable method to return the Response in its stable serialization schema
+func (r *Response) serializable() *jsonResponse {
+	cmd := make(map[string]string, len(r.command))
+	for key, val := range r.command {
+		cmd[key] = val
+	}
+	if _, exists := cmd["PASSWORD"]; exists {
+		cmd["PASSWORD"] = "***"
+	}
+	records := make([]map[string]string, 0, len(r.records))
+	for idx := range r.records {
+		records = append(records, r.records[idx].GetData())
+	}
+	columns := r.sortedColumnKeys()
+	result := &jsonResponse{
+		Code:        r.GetCode(),
+		Description: r.GetDescription(),
+		Runtime:     r.GetRuntime(),
+		Queuetime:   r.GetQueuetime(),
+		Command:     cmd,
+		Columns:     columns,
+		Records:     records,
+	}
+	if p := r.GetPagination(); p != nil {
+		result.Pagination = &jsonPagination{
+			Count:        p["COUNT"].(int),
+			CurrentPage:  p["CURRENTPAGE"].(int),
+			First:        p["FIRST"].(int),
+			Last:         p["LAST"].(int),
+			Limit:        p["LIMIT"].(int),
+			NextPage:     p["NEXTPAGE"].(int),
+			Pages:        p["PAGES"].(int),
+			PreviousPage: p["PREVIOUSPAGE"].(int),
+			Total:        p["TOTAL"].(int),
+		}
+	}
+	return result
+}
+
+// MarshalJSON method to implement json.Marshaler using a stable schema covering code,
+// description, runtime, queuetime, command (password masked), columns, records and
+// pagination data (list queries only)
+func (r *Response) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.serializable())
+}
+
+// MarshalYAML method to implement the yaml.Marshaler interface of gopkg.in/yaml.v3
+// using the same schema as MarshalJSON
+func (r *Response) MarshalYAML() (interface{}, error) {
+	return r.serializable(), nil
+}
+
+// WriteCSV method to write the records as CSV to the given writer, using the column
+// names as header row. Records are written one by one.
+func (r *Response) WriteCSV(w io.Writer) error {
+	return r.writeDelimited(w, ',')
+}
+
+// WriteTSV method to write the records as tab separated values to the given writer,
+// using the column names as header row. Records are written one by one.
+func (r *Response) WriteTSV(w io.Writer) error {
+	return r.writeDelimited(w, '\t')
+}
+
+// writeDelimited method to write the records using the given field delimiter; every row
+// is flushed to the given writer right away
+func (r *Response) writeDelimited(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	keys := r.sortedColumnKeys()
+	write := func(row []string) error {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	if err := write(keys); err != nil {
+		return err
+	}
+	row := make([]string, len(keys))
+	for idx := range r.records {
+		data := r.records[idx].GetData()
+		for i, key := range keys {
+			row[i] = data[key]
+		}
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedColumnKeys method to return the column names in alphabetical order, providing
+// a stable column order for serialization
+func (r *Response) sortedColumnKeys() []string {
+	keys := slices.Clone(r.GetColumnKeys())
+	slices.Sort(keys)
+	return keys
+}
+
+// ToPlain method to return the response in canonical plain API response format
+// including columns added via AddColumn, e.g. to craft fixtures or to cache responses
+func (r *Response) ToPlain() string {
+	hash := make(map[string]interface{}, len(r.Hash))
+	for key, val := range r.Hash {
+		if key != "PROPERTY" {
+			hash[key] = val
+		}
+	}
+	if len(r.columns) > 0 {
+		props := make(map[string][]string, len(r.columns))
+		for idx := range r.columns {
+			props[r.columns[idx].GetKey()] = r.columns[idx].GetData()
+		}
+		hash["PROPERTY"] = props
+	}
+	return rp.Serialize(hash)
+}
@@ -8,7 +8,10 @@ package response
 
 import (
 	"errors"
+	"io"
+	"maps"
 	"math"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,6 +19,7 @@ import (
 	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/column"
 	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/record"
 	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
 	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
 )
 
@@ -29,6 +33,14 @@ type Response struct {
 	columns     []column.Column
 	recordIndex int
 	records     []record.Record
+	attempts    int
+	// originalRaw represents the plain API response as returned by the backend API
+	originalRaw string
+	// originalDescription represents the description returned by the backend API
+	originalDescription string
+	// translationRule represents the ID of the translation rule applied, if any
+	translationRule string
+	translated      bool
 }
 
 const defaultCode = 421
@@ -36,28 +48,204 @@ const defaultCode = 421
 // NewResponse creates a new Response object.
 // It takes a raw string, a command map, and optional placeholder maps as parameters.
 // The function replaces the "PASSWORD" value in the command map with "***" if it exists.
-// It then translates the raw string using the command and placeholder maps.
+// It then translates the response description using the command and placeholder maps.
 // The function initializes a new Response object with the translated raw string, a hash value,
 // the command map, empty column keys and columns, a record index of 0, and an empty records slice.
 // If the hash value contains a "PROPERTY" key, the function adds columns and records to the Response object
 // based on the values in the "PROPERTY" map.
 // The function returns the newly created Response object.
 func NewResponse(raw string, cmd map[string]string, phs ...map[string]string) *Response {
-	ph := map[string]string{}
+	opts := Options{}
 	if len(phs) > 0 {
-		ph = phs[0]
+		opts.Placeholders = phs[0]
 	}
+	// parsing in non-strict mode never fails
+	r, _ := NewResponseWithOptions(raw, cmd, opts)
+	return r
+}
+
+// Options represents the options for creating a Response
+type Options struct {
+	// Placeholders covers the values of placeholders in response descriptions, e.g. CONNECTION_URL
+	Placeholders map[string]string
+	// Strict enables strict parsing, so that malformed or truncated responses are reported
+	// as responseparser.ParseErrors instead of being parsed on a best effort basis
+	Strict bool
+	// Locale represents the language of translated response descriptions, e.g. "de" or
+	// "de-CH", falling back to the parent language and finally to English
+	Locale string
+	// Templates represents the template manager to use, e.g. for the "empty" or "invalid"
+	// templates; the responsetemplatemanager singleton is used by default
+	Templates *RTM.ResponseTemplateManager
+	// Translator represents the translator to rewrite the response description with; the
+	// responsetranslator default translator is used by default
+	Translator *rt.Translator
+	// DiscardRaw drops the plain API response read by NewResponseFromReaderWithOptions once
+	// parsed, e.g. to save memory for large lists; see GetPlain and GetOriginalPlain. Plain
+	// API responses given as string are kept anyway.
+	DiscardRaw bool
+}
+
+// NewResponseFromReader creates a new Response object out of the plain API response read
+// from the given reader, e.g. an HTTP response body, parsing it while reading.
+// See NewResponse for details. Only read errors are returned.
+func NewResponseFromReader(body io.Reader, cmd map[string]string, phs ...map[string]string) (*Response, error) {
+	opts := Options{}
+	if len(phs) > 0 {
+		opts.Placeholders = phs[0]
+	}
+	return NewResponseFromReaderWithOptions(body, cmd, opts)
+}
+
+// NewResponseWithOptions creates a new Response object using the given options.
+// In strict mode, a response covering the "invalid" template (or the "empty" template for
+// empty API responses) is returned together with the responseparser.ParseErrors in case
+// the raw API response is malformed or truncated.
+func NewResponseWithOptions(raw string, cmd map[string]string, opts Options) (*Response, error) {
+	if len(raw) > 0 {
+		// explicit call for a static template, e.g. "404" or "httperror|<error>"
+		id, httperror, isHTTPError := raw, "", false
+		if rest, ok := strings.CutPrefix(raw, "httperror|"); ok {
+			id, httperror, isHTTPError = "httperror", rest, true
+		}
+		if tpl, ok := opts.templates().LookupTemplate(id); ok {
+			if isHTTPError && len(httperror) > 0 {
+				tpl = strings.ReplaceAll(tpl, "{HTTPERROR}", " ("+httperror+")")
+			}
+			r := translate(tpl, rp.Parse(tpl), true, raw, map[string]interface{}{}, cmd, opts)
+			r.translated = true
+			return r, nil
+		}
+	}
+	hash, err := opts.parse(strings.NewReader(raw))
+	return newParsedResponse(raw, true, len(raw) == 0, hash, err, cmd, opts)
+}
+
+// NewResponseFromReaderWithOptions creates a new Response object out of the plain API response
+// read from the given reader using the given options. See NewResponseWithOptions for details;
+// in case of read errors, no response is returned. The API response is parsed while reading
+// and kept unless Options.DiscardRaw is set.
+func NewResponseFromReaderWithOptions(body io.Reader, cmd map[string]string, opts Options) (*Response, error) {
+	var raw *strings.Builder
+	if !opts.DiscardRaw {
+		raw = &strings.Builder{}
+		body = io.TeeReader(body, raw)
+	}
+	cr := &countingReader{r: body}
+	hash, err := opts.parse(cr)
+	var perr rp.ParseErrors
+	if err != nil && !errors.As(err, &perr) {
+		return nil, err
+	}
+	if raw == nil {
+		return newParsedResponse("", false, cr.n == 0, hash, err, cmd, opts)
+	}
+	return newParsedResponse(raw.String(), true, cr.n == 0, hash, err, cmd, opts)
+}
+
+// templates method to return the template manager to use
+func (opts *Options) templates() *RTM.ResponseTemplateManager {
+	if opts.Templates != nil {
+		return opts.Templates
+	}
+	return RTM.GetInstance()
+}
+
+// translator method to return the translator to use
+func (opts *Options) translator() *rt.Translator {
+	if opts.Translator != nil {
+		return opts.Translator
+	}
+	return rt.DefaultTranslator()
+}
+
+// parse method to return the plain API response read from the given reader parsed into
+// hash format, using strict parsing if enabled
+func (opts *Options) parse(r io.Reader) (map[string]interface{}, error) {
+	if opts.Strict {
+		return rp.ParseReaderStrict(r)
+	}
+	return rp.ParseReader(r)
+}
+
+// countingReader represents a reader counting the bytes read
+type countingReader struct {
+	r io.Reader
+	n int64
+}
 
+// Read method to implement io.Reader
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// newParsedResponse function to create the Response out of the given parsed API response
+// and its plain format, if kept. Empty, invalid and (in strict mode) malformed API responses
+// are replaced by the "empty" and "invalid" templates; parse errors are passed through.
+func newParsedResponse(raw string, keepRaw bool, empty bool, hash map[string]interface{}, err error, cmd map[string]string, opts Options) (*Response, error) {
+	tplID := ""
+	switch {
+	case empty:
+		tplID = "empty"
+	case err != nil:
+		tplID = "invalid"
+	default:
+		// Missing CODE or DESCRIPTION in API Response
+		_, hasCode := hash["CODE"]
+		_, hasDescription := hash["DESCRIPTION"]
+		if !hasCode || !hasDescription {
+			tplID = "invalid"
+		}
+	}
+	if tpl, ok := opts.templates().LookupTemplate(tplID); ok {
+		r := translate(tpl, rp.Parse(tpl), true, raw, hash, cmd, opts)
+		r.translated = true
+		return r, err
+	}
+	return translate(raw, hash, keepRaw, raw, hash, cmd, opts), err
+}
+
+var descriptionLinePattern = regexp.MustCompile(`(?im)^[\t ]*description[\t ]*=[^\r\n]*`)
+
+// translate function to create the Response out of the given parsed API response with its
+// description translated; the plain format is updated accordingly if kept. The original API
+// response is kept as reference.
+func translate(raw string, hash map[string]interface{}, keepRaw bool, originalRaw string, original map[string]interface{}, cmd map[string]string, opts Options) *Response {
 	newcmd := cmd
 	_, exists := newcmd["PASSWORD"]
 	if exists {
 		newcmd["PASSWORD"] = "***"
 	}
-	newraw := rt.Translate(raw, cmd, ph)
+	description, _ := hash["DESCRIPTION"].(string)
+	newdescription, ruleID := opts.translator().TranslateDescription(description, cmd, opts.Locale, opts.Placeholders)
+	if newdescription != description {
+		// keep the given hash untouched, it may represent the original API response
+		hash = maps.Clone(hash)
+		hash["DESCRIPTION"] = newdescription
+		if keepRaw {
+			raw = descriptionLinePattern.ReplaceAllLiteralString(raw, "description = "+newdescription)
+		}
+	}
+	if !keepRaw {
+		raw = ""
+	}
+	r := newResponse(raw, hash, newcmd)
+	r.originalRaw = originalRaw
+	r.originalDescription, _ = original["DESCRIPTION"].(string)
+	r.translationRule = ruleID
+	r.translated = newdescription != description
+	return r
+}
+
+// newResponse function to create a new Response object out of the given raw API
+// response and its parsed hash
+func newResponse(raw string, hash map[string]interface{}, cmd map[string]string) *Response {
 	r := &Response{
-		Raw:         newraw,
-		Hash:        rp.Parse(newraw),
-		command:     newcmd,
+		Raw:         raw,
+		Hash:        hash,
+		command:     cmd,
 		columnkeys:  []string{},
 		columns:     []column.Column{},
 		recordIndex: 0,
@@ -81,15 +269,12 @@ func NewResponse(raw string, cmd map[string]string, phs ...map[string]string) *R
 				}
 			}
 		}
+		r.records = make([]record.Record, 0, count)
 		for i := 0; i < count; i++ {
-			d := map[string]string{}
-			for _, k := range colKeys {
-				col := r.GetColumn(k)
-				if col != nil {
-					v, err := col.GetDataByIndex(i)
-					if err == nil {
-						d[k] = v
-					}
+			d := make(map[string]string, len(r.columns))
+			for idx := range r.columns {
+				if data := r.columns[idx].GetData(); i < len(data) {
+					d[r.columns[idx].GetKey()] = data[i]
 				}
 			}
 			r.AddRecord(d)
@@ -147,8 +332,37 @@ func (r *Response) GetDescription() string {
 	return desc
 }
 
-// GetPlain method to return raw API response
+// GetOriginalDescription method to return the API response description as returned by the
+// backend API, i.e. before translation; empty if the backend API did not return any
+func (r *Response) GetOriginalDescription() string {
+	return r.originalDescription
+}
+
+// GetOriginalPlain method to return the plain API response as returned by the backend API,
+// i.e. before translation. It is empty in case the raw API response was discarded, see
+// Options.DiscardRaw; GetOriginalDescription is available anyway.
+func (r *Response) GetOriginalPlain() string {
+	return r.originalRaw
+}
+
+// GetTranslationRule method to return the ID of the translation rule that rewrote the
+// API response description; empty if no rule matched
+func (r *Response) GetTranslationRule() string {
+	return r.translationRule
+}
+
+// IsTranslated method to check if the plain API response was rewritten by translation,
+// i.e. by a translation rule or by replacing it with a template (e.g. for empty responses)
+func (r *Response) IsTranslated() bool {
+	return r.translated
+}
+
+// GetPlain method to return raw API response. In case it was discarded (see Options.DiscardRaw),
+// the API response is returned in canonical plain format.
 func (r *Response) GetPlain() string {
+	if len(r.Raw) == 0 && len(r.Hash) > 0 {
+		return rp.Serialize(r.Hash)
+	}
 	return r.Raw
 }
 
@@ -164,6 +378,17 @@ func (r *Response) GetQueuetime() float64 {
 	return 0.00
 }
 
+// GetAttempts method to return the number of request attempts made to get this API response
+func (r *Response) GetAttempts() int {
+	return r.attempts
+}
+
+// SetAttempts method to set the number of request attempts made to get this API response
+func (r *Response) SetAttempts(attempts int) *Response {
+	r.attempts = attempts
+	return r
+}
+
 // GetHash method to return API response in hash format
 func (r *Response) GetHash() map[string]interface{} {
 	return r.Hash
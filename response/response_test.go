@@ -1,17 +1,26 @@
 package response
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/record"
+	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
 	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
+	"github.com/stretchr/testify/assert"
 )
 
-var rtm = RTM.GetInstance()
+var rtm = RTM.NewResponseTemplateManager()
 
 func TestMain(m *testing.M) {
 	rtm.AddTemplate(
@@ -379,3 +388,335 @@ func TestIsPending(t *testing.T) {
 		t.Errorf("isPending() = %v, want true", got)
 	}
 }
+
+type testDomain struct {
+	Domain      string    `api:"DOMAIN"`
+	Renewal     bool      `api:"RENEWAL"`
+	Period      int       `api:"PERIOD"`
+	Price       float64   `api:"PRICE"`
+	Expiration  time.Time `api:"EXPIRATION DATE"`
+	Nameservers []string  `api:"NAMESERVER"`
+	Ignored     string
+}
+
+func TestUnmarshal(t *testing.T) {
+	r := NewResponse("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\n"+
+		"property[domain][0] = example.com\r\nproperty[renewal][0] = 1\r\nproperty[period][0] = 2\r\nproperty[price][0] = 9.95\r\n"+
+		"property[expiration date][0] = 2024-09-19 10:52:51.0\r\nproperty[nameserver][0] = ns1.example.net\r\nproperty[nameserver][1] = ns2.example.net\r\nEOF\r\n", map[string]string{})
+	var d testDomain
+	if err := Unmarshal(r, &d); err != nil {
+		t.Fatalf("TestUnmarshal: Expected not to run into error: %v", err)
+	}
+	assert.Equal(t, testDomain{
+		Domain:      "example.com",
+		Renewal:     true,
+		Period:      2,
+		Price:       9.95,
+		Expiration:  time.Date(2024, 9, 19, 10, 52, 51, 0, time.UTC),
+		Nameservers: []string{"ns1.example.net", "ns2.example.net"},
+	}, d)
+
+	if err := Unmarshal(r, d); err == nil {
+		t.Error("TestUnmarshal: Expected error for non-pointer target.")
+	}
+	var s []string
+	if err := Unmarshal(r, &s); err == nil {
+		t.Error("TestUnmarshal: Expected error for slice of non-structs.")
+	}
+}
+
+func TestUnmarshalList(t *testing.T) {
+	r := NewResponse(rtm.GetTemplate("listP0"), map[string]string{})
+	type listEntry struct {
+		Domain string `api:"domain"`
+		Total  *int   `api:"TOTAL"`
+	}
+	var list []listEntry
+	if err := Unmarshal(r, &list); err != nil {
+		t.Fatalf("TestUnmarshalList: Expected not to run into error: %v", err)
+	}
+	assert.Len(t, list, 2)
+	assert.Equal(t, "cnic-ssl-test1.com", list[0].Domain)
+	assert.Equal(t, "cnic-ssl-test2.com", list[1].Domain)
+	if assert.NotNil(t, list[0].Total) {
+		assert.Equal(t, 4, *list[0].Total)
+	}
+	assert.Nil(t, list[1].Total)
+
+	var ptrs []*listEntry
+	assert.NoError(t, Unmarshal(r, &ptrs))
+	assert.Len(t, ptrs, 2)
+	assert.Equal(t, "cnic-ssl-test2.com", ptrs[1].Domain)
+}
+
+func TestUnmarshalRecord(t *testing.T) {
+	rec := record.NewRecord(map[string]string{
+		"DOMAIN":      "example.com",
+		"RENEWAL":     "0",
+		"PERIOD":      "",
+		"PRICE":       "1",
+		"NAMESERVER0": "ns1.example.net",
+		"NAMESERVER1": "ns2.example.net",
+	})
+	var d testDomain
+	if err := UnmarshalRecord(rec, &d); err != nil {
+		t.Fatalf("TestUnmarshalRecord: Expected not to run into error: %v", err)
+	}
+	assert.Equal(t, "example.com", d.Domain)
+	assert.False(t, d.Renewal)
+	assert.Equal(t, 0, d.Period)
+	assert.Equal(t, 1.0, d.Price)
+	assert.True(t, d.Expiration.IsZero())
+	assert.Equal(t, []string{"ns1.example.net", "ns2.example.net"}, d.Nameservers)
+
+	invalid := record.NewRecord(map[string]string{"PERIOD": "one"})
+	err := UnmarshalRecord(invalid, &d)
+	if err == nil || !strings.Contains(err.Error(), "PERIOD") {
+		t.Errorf("TestUnmarshalRecord: Expected conversion error for column PERIOD, got %v", err)
+	}
+	assert.Error(t, UnmarshalRecord(record.NewRecord(map[string]string{"RENEWAL": "maybe"}), &d))
+	assert.Error(t, UnmarshalRecord(record.NewRecord(map[string]string{"EXPIRATION DATE": "19.09.2024"}), &d))
+	assert.Error(t, UnmarshalRecord(nil, &d))
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	type strictDomain struct {
+		Domain string `api:"DOMAIN"`
+		Status string `api:"STATUS"`
+	}
+	rec := record.NewRecord(map[string]string{"DOMAIN": "example.com", "RENEWAL": "1"})
+	var d strictDomain
+	assert.NoError(t, UnmarshalRecord(rec, &d))
+	err := UnmarshalRecord(rec, &d, UnmarshalOptions{Strict: true})
+	if err == nil {
+		t.Fatal("TestUnmarshalStrict: Expected error for unknown and missing columns.")
+	}
+	assert.Equal(t, "unknown columns RENEWAL; missing columns STATUS", err.Error())
+
+	r := NewResponse(rtm.GetTemplate("listP0"), map[string]string{})
+	var list []strictDomain
+	assert.Error(t, Unmarshal(r, &list, UnmarshalOptions{Strict: true}))
+	assert.Error(t, Unmarshal(r, &d, UnmarshalOptions{Strict: true}))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	r := NewResponse(rtm.GetTemplate("listP0"), map[string]string{"COMMAND": "QueryDomainList", "PASSWORD": "secret"})
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("TestMarshalJSON: Expected not to run into error: %v", err)
+	}
+	expected := `{"code":200,"description":"Command completed successfully","runtime":0.007,"queuetime":0,` +
+		`"command":{"COMMAND":"QueryDomainList","PASSWORD":"***"},` +
+		`"columns":["COUNT","DOMAIN","FIRST","LAST","LIMIT","TOTAL"],` +
+		`"records":[{"COUNT":"2","DOMAIN":"cnic-ssl-test1.com","FIRST":"0","LAST":"1","LIMIT":"2","TOTAL":"4"},{"DOMAIN":"cnic-ssl-test2.com"}],` +
+		`"pagination":{"count":2,"currentpage":1,"first":0,"last":1,"limit":2,"nextpage":2,"pages":2,"previouspage":1,"total":4}}`
+	assert.JSONEq(t, expected, string(data))
+
+	// stable output
+	again, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+
+	data, err = json.Marshal(NewResponse(rtm.GetTemplate("OK"), map[string]string{"COMMAND": "StatusAccount"}))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "pagination")
+	assert.Contains(t, string(data), `"records":[]`)
+}
+
+func TestMarshalYAML(t *testing.T) {
+	r := NewResponse(rtm.GetTemplate("listP0"), map[string]string{"COMMAND": "QueryDomainList"})
+	v, err := r.MarshalYAML()
+	assert.NoError(t, err)
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	expected, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(data))
+}
+
+func TestWriteCSV(t *testing.T) {
+	r := NewResponse("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\n"+
+		"property[domain][0] = example.com\r\nproperty[domain][1] = example.net\r\n"+
+		"property[note][0] = a, \"quoted\" note\r\nEOF\r\n", map[string]string{})
+	var buf bytes.Buffer
+	assert.NoError(t, r.WriteCSV(&buf))
+	assert.Equal(t, "DOMAIN,NOTE\nexample.com,\"a, \"\"quoted\"\" note\"\nexample.net,\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, r.WriteTSV(&buf))
+	assert.Equal(t, "DOMAIN\tNOTE\nexample.com\t\"a, \"\"quoted\"\" note\"\nexample.net\t\n", buf.String())
+
+	// rows are flushed one by one
+	w := &countingWriter{}
+	assert.NoError(t, r.WriteCSV(w))
+	assert.Equal(t, 3, w.writes)
+}
+
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func TestToPlain(t *testing.T) {
+	r := NewResponse(rtm.GetTemplate("listP0"), map[string]string{"COMMAND": "QueryDomainList"})
+	plain := r.ToPlain()
+	assert.True(t, strings.HasPrefix(plain, "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\n"))
+	assert.Contains(t, plain, "property[DOMAIN][1] = cnic-ssl-test2.com\r\n")
+	assert.True(t, strings.HasSuffix(plain, "EOF\r\n"))
+	assert.Equal(t, r.GetHash(), NewResponse(plain, map[string]string{}).GetHash())
+
+	r = NewResponse(rtm.GetTemplate("OK"), map[string]string{})
+	r.AddColumn("DOMAIN", []string{"example.com"})
+	assert.Equal(t, "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nproperty[DOMAIN][0] = example.com\r\nEOF\r\n", r.ToPlain())
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestNewResponseFromReader(t *testing.T) {
+	raw := rtm.GetTemplate("listP0")
+	r, err := NewResponseFromReaderWithOptions(strings.NewReader(raw), map[string]string{"COMMAND": "QueryDomainList", "PASSWORD": "secret"}, Options{})
+	if err != nil {
+		t.Fatalf("TestNewResponseFromReader: Expected not to run into error: %v", err)
+	}
+	expected := NewResponse(raw, map[string]string{"COMMAND": "QueryDomainList", "PASSWORD": "secret"})
+	assert.Equal(t, expected.GetPlain(), r.GetPlain())
+	assert.Equal(t, expected.GetHash(), r.GetHash())
+
+	// the raw body can be discarded, the plain text is serialized from the hash then
+	r, err = NewResponseFromReaderWithOptions(strings.NewReader(raw), map[string]string{"COMMAND": "QueryDomainList", "PASSWORD": "secret"}, Options{DiscardRaw: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "", r.GetOriginalPlain())
+	assert.Equal(t, expected.GetHash(), rp.Parse(r.GetPlain()))
+	assert.Equal(t, expected.GetHash(), r.GetHash())
+	assert.Equal(t, expected.GetRecords(), r.GetRecords())
+	assert.Equal(t, "***", r.GetCommand()["PASSWORD"])
+
+	// translated responses are parsed again
+	r, err = NewResponseFromReader(strings.NewReader(""), map[string]string{"COMMAND": "StatusAccount"})
+	assert.NoError(t, err)
+	assert.Equal(t, 423, r.GetCode())
+
+	_, err = NewResponseFromReader(io.MultiReader(strings.NewReader(raw[:20]), failingReader{}), map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestNewResponseWithOptions(t *testing.T) {
+	raw := "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nproperty[domain][1] = example.net\r\nproperty[domain][0] = example.com\r\nEOF\r\n"
+	r, err := NewResponseWithOptions(raw, map[string]string{}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.net", "example.com"}, r.GetColumn("DOMAIN").GetData())
+
+	r, err = NewResponseWithOptions(raw, map[string]string{}, Options{Strict: true})
+	var perrs rp.ParseErrors
+	if !errors.As(err, &perrs) {
+		t.Errorf("TestNewResponseWithOptions: Expected parse errors, got %v", err)
+	}
+	assert.Equal(t, 423, r.GetCode())
+	assert.Equal(t, 0, r.GetRecordsCount())
+
+	// truncated bodies are not mistaken for valid empty results
+	r, err = NewResponseFromReaderWithOptions(strings.NewReader("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\n"), map[string]string{}, Options{Strict: true})
+	assert.Error(t, err)
+	assert.False(t, r.IsSuccess())
+
+	// also when the description is missing, so the "invalid" template applies anyway
+	r, err = NewResponseFromReaderWithOptions(strings.NewReader("[RESPONSE]\r\ncode = 200\r\n"), map[string]string{}, Options{Strict: true})
+	if !errors.As(err, &perrs) {
+		t.Errorf("TestNewResponseWithOptions: Expected parse errors for truncated response, got %v", err)
+	}
+	assert.Equal(t, 423, r.GetCode())
+	assert.Equal(t, "Invalid API response. Contact Support", r.GetDescription())
+
+	r, err = NewResponseWithOptions("", map[string]string{}, Options{Strict: true, Placeholders: map[string]string{"CONNECTION_URL": "https://localhost"}})
+	assert.ErrorAs(t, err, &perrs)
+	assert.Equal(t, 423, r.GetCode())
+	assert.Contains(t, r.GetDescription(), "https://localhost")
+
+	_, err = NewResponseFromReaderWithOptions(failingReader{}, map[string]string{}, Options{Strict: true})
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &perrs))
+}
+
+func TestLocale(t *testing.T) {
+	raw := "[RESPONSE]\r\ncode = 505\r\ndescription = Syntax error in Parameter DOMAIN (my–domain.de)\r\nEOF\r\n"
+	cases := map[string]string{
+		"":      "The Domain Name my–domain.de is invalid.",
+		"de":    "Der Domainname my–domain.de ist ungültig.",
+		"de-CH": "Der Domainname my–domain.de ist ungültig.",
+		"fr_FR": "Le nom de domaine my–domain.de est invalide.",
+		"es":    "El nombre de dominio my–domain.de no es válido.",
+		"nl":    "The Domain Name my–domain.de is invalid.",
+	}
+	for locale, expected := range cases {
+		r, err := NewResponseWithOptions(raw, map[string]string{"COMMAND": "AddDomain"}, Options{Locale: locale})
+		assert.NoError(t, err)
+		if r.GetDescription() != expected {
+			t.Errorf("TestLocale: Expected description %q for locale %q, got %q", expected, locale, r.GetDescription())
+		}
+		assert.Equal(t, "Syntax error in Parameter DOMAIN (my–domain.de)", r.GetOriginalDescription())
+	}
+}
+
+func TestGetOriginalDescription(t *testing.T) {
+	r := NewResponse("[RESPONSE]\r\ncode = 530\r\ndescription = Authorization failed; Operation forbidden by ACL\r\nEOF\r\n", map[string]string{"COMMAND": "StatusAccount"})
+	assert.Equal(t, "Authorization failed; Used Command `StatusAccount` not white-listed by your Access Control List", r.GetDescription())
+	assert.Equal(t, "Authorization failed; Operation forbidden by ACL", r.GetOriginalDescription())
+
+	r = NewResponse("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nEOF\r\n", map[string]string{"COMMAND": "StatusAccount"})
+	assert.Equal(t, "Command completed successfully", r.GetOriginalDescription())
+
+	// no description returned by the backend API
+	r = NewResponse("", map[string]string{"COMMAND": "StatusAccount"})
+	assert.Equal(t, "", r.GetOriginalDescription())
+}
+
+func TestOptionsTranslator(t *testing.T) {
+	raw := "[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n"
+	tr := rt.NewTranslator()
+	assert.NoError(t, tr.Register(rt.Rule{ID: "locked", Type: rt.RulePrefix, Pattern: "Domain status", Replacement: "Locked: {DOMAIN}"}))
+	r, err := NewResponseWithOptions(raw, map[string]string{"COMMAND": "TransferDomain", "DOMAIN": "example.com"}, Options{Translator: tr})
+	assert.NoError(t, err)
+	assert.Equal(t, "Locked: example.com", r.GetDescription())
+	assert.Equal(t, "locked", r.GetTranslationRule())
+
+	// the default translator is used otherwise
+	r = NewResponse(raw, map[string]string{"COMMAND": "TransferDomain"})
+	assert.Equal(t, "domain-locked", r.GetTranslationRule())
+}
+
+func TestTranslationMetadata(t *testing.T) {
+	raw := "[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n"
+	r := NewResponse(raw, map[string]string{"COMMAND": "TransferDomain"})
+	assert.True(t, r.IsTranslated())
+	assert.Equal(t, "domain-locked", r.GetTranslationRule())
+	assert.Equal(t, raw, r.GetOriginalPlain())
+	assert.NotEqual(t, raw, r.GetPlain())
+
+	r, err := NewResponseFromReaderWithOptions(strings.NewReader(raw), map[string]string{"COMMAND": "TransferDomain"}, Options{Locale: "es"})
+	assert.NoError(t, err)
+	assert.Equal(t, "domain-locked", r.GetTranslationRule())
+	assert.Equal(t, raw, r.GetOriginalPlain())
+	assert.Equal(t, "Domain status does not allow for operation", r.GetOriginalDescription())
+
+	raw = "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nEOF\r\n"
+	r = NewResponse(raw, map[string]string{"COMMAND": "StatusAccount"})
+	if r.IsTranslated() {
+		t.Error("TestTranslationMetadata: Expected response not to be translated")
+	}
+	assert.Equal(t, "", r.GetTranslationRule())
+	assert.Equal(t, raw, r.GetOriginalPlain())
+
+	// replaced by template
+	r = NewResponse("", map[string]string{"COMMAND": "StatusAccount"})
+	assert.True(t, r.IsTranslated())
+	assert.Equal(t, "", r.GetTranslationRule())
+	assert.Equal(t, "", r.GetOriginalPlain())
+}
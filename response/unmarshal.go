@@ -0,0 +1,333 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package response
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/record"
+)
+
+// TimeFormat represents the format of timestamps in API responses
+const TimeFormat = "2006-01-02 15:04:05"
+
+// UnmarshalOptions represents the options for unmarshalling response data into structs
+type UnmarshalOptions struct {
+	// Strict causes an error for columns not mapped to any struct field and for
+	// struct fields without corresponding column.
+	Strict bool
+}
+
+// Unmarshal function to map the response data into the value pointed to by v using the
+// `api` struct tags to match column names (case insensitive), e.g. `api:"DOMAIN"`.
+//
+// If v points to a slice of structs (or struct pointers), each record is mapped into an
+// element; []string fields are filled from indexed columns such as NAMESERVER0, NAMESERVER1.
+// If v points to a struct, the first row is mapped into it and []string fields are
+// filled from all rows of the column, e.g. the nameservers of a StatusDomain response.
+//
+// Supported field types are string, int, uint, bool, float64, time.Time, []string and
+// pointers to them. Empty values are mapped to the zero value.
+func Unmarshal(r *Response, v interface{}, opts ...UnmarshalOptions) error {
+	options := UnmarshalOptions{}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer, got %T", v)
+	}
+	rv = rv.Elem()
+	switch rv.Kind() {
+	case reflect.Struct:
+		data := map[string][]string{}
+		for _, col := range r.GetColumns() {
+			data[col.GetKey()] = col.GetData()
+		}
+		return unmarshalColumns(data, rv, options)
+	case reflect.Slice:
+		elemType := rv.Type().Elem()
+		isPtr := elemType.Kind() == reflect.Ptr
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct {
+			return fmt.Errorf("unmarshal target must point to a slice of structs, got %T", v)
+		}
+		records := r.GetRecords()
+		result := reflect.MakeSlice(rv.Type(), 0, len(records))
+		for idx := range records {
+			elem := reflect.New(elemType)
+			if err := unmarshalRecord(records[idx].GetData(), elem.Elem(), options); err != nil {
+				return fmt.Errorf("record %d: %w", idx, err)
+			}
+			if !isPtr {
+				elem = elem.Elem()
+			}
+			result = reflect.Append(result, elem)
+		}
+		rv.Set(result)
+		return nil
+	}
+	return fmt.Errorf("unmarshal target must point to a struct or a slice of structs, got %T", v)
+}
+
+// UnmarshalRecord function to map the given record into the struct pointed to by v.
+// See Unmarshal for details.
+func UnmarshalRecord(rec *record.Record, v interface{}, opts ...UnmarshalOptions) error {
+	options := UnmarshalOptions{}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer to a struct, got %T", v)
+	}
+	if rec == nil {
+		return errors.New("record is nil")
+	}
+	return unmarshalRecord(rec.GetData(), rv.Elem(), options)
+}
+
+// apiField represents a struct field mapped to a column
+type apiField struct {
+	index  int
+	column string
+}
+
+// apiFields function to return the struct fields mapped by `api` struct tags
+func apiFields(t reflect.Type) []apiField {
+	fields := []apiField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("api")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" || len(name) == 0 {
+			continue
+		}
+		fields = append(fields, apiField{index: i, column: strings.ToUpper(name)})
+	}
+	return fields
+}
+
+// unmarshalRecord function to map the given record data into the given struct value
+func unmarshalRecord(data map[string]string, rv reflect.Value, options UnmarshalOptions) error {
+	known := map[string]bool{}
+	missing := []string{}
+	for _, f := range apiFields(rv.Type()) {
+		fv := rv.Field(f.index)
+		if isStringSlice(fv.Type()) {
+			// collect indexed columns, e.g. NAMESERVER0, NAMESERVER1
+			list := []string{}
+			for idx := 0; ; idx++ {
+				key := f.column + strconv.Itoa(idx)
+				val, ok := data[key]
+				if !ok {
+					break
+				}
+				known[key] = true
+				list = append(list, val)
+			}
+			if val, ok := data[f.column]; ok && len(list) == 0 {
+				known[f.column] = true
+				list = append(list, val)
+			}
+			if len(list) == 0 {
+				missing = append(missing, f.column)
+				continue
+			}
+			setStrings(fv, list)
+			continue
+		}
+		val, ok := data[f.column]
+		if !ok {
+			missing = append(missing, f.column)
+			continue
+		}
+		known[f.column] = true
+		if err := setValue(fv, val); err != nil {
+			return fmt.Errorf("column %s: %w", f.column, err)
+		}
+	}
+	if options.Strict {
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		return strictError(keys, known, missing)
+	}
+	return nil
+}
+
+// unmarshalColumns function to map the given column data into the given struct value
+func unmarshalColumns(data map[string][]string, rv reflect.Value, options UnmarshalOptions) error {
+	known := map[string]bool{}
+	missing := []string{}
+	for _, f := range apiFields(rv.Type()) {
+		vals, ok := data[f.column]
+		if !ok {
+			missing = append(missing, f.column)
+			continue
+		}
+		known[f.column] = true
+		fv := rv.Field(f.index)
+		if isStringSlice(fv.Type()) {
+			setStrings(fv, vals)
+		} else if len(vals) > 0 {
+			if err := setValue(fv, vals[0]); err != nil {
+				return fmt.Errorf("column %s: %w", f.column, err)
+			}
+		}
+	}
+	if options.Strict {
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		return strictError(keys, known, missing)
+	}
+	return nil
+}
+
+// strictError function to return an error listing unknown and missing columns, if any
+func strictError(keys []string, known map[string]bool, missing []string) error {
+	unknown := []string{}
+	for _, key := range keys {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	msgs := []string{}
+	if len(unknown) > 0 {
+		msgs = append(msgs, "unknown columns "+strings.Join(unknown, ", "))
+	}
+	if len(missing) > 0 {
+		msgs = append(msgs, "missing columns "+strings.Join(missing, ", "))
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// isStringSlice function to check if the given type is a (pointer to a) string slice
+func isStringSlice(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String
+}
+
+// setStrings function to set the given string slice field
+func setStrings(fv reflect.Value, vals []string) {
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(reflect.New(fv.Type().Elem()))
+		fv = fv.Elem()
+	}
+	list := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+	for idx, val := range vals {
+		list.Index(idx).SetString(val)
+	}
+	fv.Set(list)
+}
+
+// setValue function to convert the given column value into the type of the given field
+func setValue(fv reflect.Value, val string) error {
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setValue(ptr.Elem(), val); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if fv.Type() == reflect.TypeOf(time.Time{}) {
+		if len(val) == 0 {
+			fv.Set(reflect.ValueOf(time.Time{}))
+			return nil
+		}
+		t, err := parseTime(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if len(val) == 0 {
+			fv.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(val) == 0 {
+			fv.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if len(val) == 0 {
+			fv.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := parseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// parseBool function to convert the given API boolean value
+func parseBool(val string) (bool, error) {
+	switch strings.ToUpper(val) {
+	case "1", "TRUE", "YES", "ON":
+		return true, nil
+	case "", "0", "FALSE", "NO", "OFF":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value %q", val)
+}
+
+// parseTime function to convert the given API timestamp (UTC), e.g. "2023-05-22 12:14:31.0"
+func parseTime(val string) (time.Time, error) {
+	for _, layout := range []string{TimeFormat, "2006-01-02"} {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", val)
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+// Package responseconfig provides functionality to load response templates, translation
+// rules and their localised catalogues from YAML or JSON files, e.g.
+//
+//	placeholders: [DOMAIN]
+//	templates:
+//	  - id: maintenance
+//	    code: "421"
+//	    description: API under maintenance, see {CONNECTION_URL}
+//	rules:
+//	  - id: domain-taken
+//	    type: regex
+//	    pattern: Object exists; (.+)
+//	    replacement: The Domain {DOMAIN} is already registered ($1).
+//	    priority: 10
+//	catalogs:
+//	  de:
+//	    domain-taken: Die Domain {DOMAIN} ist bereits registriert ($1).
+package responseconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
+	"gopkg.in/yaml.v3"
+)
+
+// knownPlaceholders represents the placeholders always available in rule replacements;
+// further command parameters can be declared per configuration
+var knownPlaceholders = []string{"COMMAND", "CONNECTION_URL", "HTTPERROR"}
+
+// templatePlaceholders represents the only placeholders replaced in templates, command
+// parameters are not available there
+var templatePlaceholders = map[string]bool{"CONNECTION_URL": true, "HTTPERROR": true}
+
+// Config represents the contents of a configuration file
+type Config struct {
+	// Placeholders declares the additional placeholders used, e.g. command parameters like DOMAIN
+	Placeholders []string `json:"placeholders" yaml:"placeholders"`
+	// Templates covers the response templates to add or override
+	Templates []Template `json:"templates" yaml:"templates"`
+	// Rules covers the translation rules to register or override
+	Rules []Rule `json:"rules" yaml:"rules"`
+	// Catalogs covers the localised rule replacements per locale
+	Catalogs map[string]rt.Catalog `json:"catalogs" yaml:"catalogs"`
+}
+
+// Template represents a response template entry
+type Template struct {
+	ID          string `json:"id" yaml:"id"`
+	Code        string `json:"code" yaml:"code"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// Rule represents a translation rule entry, see responsetranslator.Rule
+type Rule struct {
+	ID          string `json:"id" yaml:"id"`
+	Type        string `json:"type" yaml:"type"`
+	Pattern     string `json:"pattern" yaml:"pattern"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+	Priority    int    `json:"priority" yaml:"priority"`
+}
+
+// EntryError represents an invalid configuration entry
+type EntryError struct {
+	Entry  string // Entry identifies the entry, e.g. rules[2] (acl-forbidden)
+	Reason string // Reason describes the issue
+}
+
+// Error method to implement the error interface
+func (e *EntryError) Error() string {
+	return e.Entry + ": " + e.Reason
+}
+
+// ValidationErrors represents the list of all invalid configuration entries
+type ValidationErrors []*EntryError
+
+// Error method to implement the error interface, listing all issues
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid response configuration: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap method to return the single issues for use with errors.Is and errors.As
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// LoadFile function to load and validate the configuration of the given YAML (.yaml, .yml)
+// or JSON (.json) file
+func LoadFile(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, path.Ext(file))
+}
+
+// Load function to load and validate the configuration of the given YAML or JSON file
+// of the given file system, e.g. an embed.FS
+func Load(fsys fs.FS, name string) (*Config, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, path.Ext(name))
+}
+
+// Decode function to decode and validate the given configuration data in the format of
+// the given file extension (.yaml, .yml or .json). Unknown fields are rejected; invalid
+// entries are reported all at once as ValidationErrors. Empty data results in an empty
+// configuration for both formats.
+func Decode(data []byte, ext string) (*Config, error) {
+	cfg := &Config{}
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid response configuration: %w", err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid response configuration: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response configuration format %q", ext)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+var groupPattern = regexp.MustCompile(`\$(\d+)|\$\{(\d+)\}`)
+
+// Validate method to check all entries of the configuration and to return the issues
+// found as ValidationErrors
+func (c *Config) Validate() error {
+	known := map[string]bool{}
+	for _, name := range append(slices.Clone(knownPlaceholders), c.Placeholders...) {
+		known[name] = true
+	}
+	errs := ValidationErrors{}
+	fail := func(entry string, format string, args ...interface{}) {
+		errs = append(errs, &EntryError{Entry: entry, Reason: fmt.Sprintf(format, args...)})
+	}
+	checkPlaceholders := func(entry string, text string, known map[string]bool) {
+		// group references like ${1} are no placeholders
+		text = groupPattern.ReplaceAllString(text, "")
+		for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !known[m[1]] {
+				fail(entry, "unknown placeholder {%s}", m[1])
+			}
+		}
+	}
+
+	ids := map[string]bool{}
+	for idx, tpl := range c.Templates {
+		entry := fmt.Sprintf("templates[%d]", idx)
+		if len(tpl.ID) == 0 {
+			fail(entry, "missing id")
+		} else {
+			entry += " (" + tpl.ID + ")"
+			if ids[tpl.ID] {
+				fail(entry, "duplicate id")
+			}
+			ids[tpl.ID] = true
+		}
+		if _, err := strconv.Atoi(tpl.Code); err != nil || len(tpl.Code) != 3 {
+			fail(entry, "invalid code %q", tpl.Code)
+		}
+		if len(tpl.Description) == 0 {
+			fail(entry, "missing description")
+		}
+		checkPlaceholders(entry, tpl.Description, templatePlaceholders)
+	}
+
+	groups := map[string]int{}
+	ids = map[string]bool{}
+	for idx, rule := range c.Rules {
+		entry := fmt.Sprintf("rules[%d]", idx)
+		if len(rule.ID) == 0 {
+			fail(entry, "missing id")
+		} else {
+			entry += " (" + rule.ID + ")"
+			if ids[rule.ID] {
+				fail(entry, "duplicate id")
+			}
+			ids[rule.ID] = true
+		}
+		// group references are checked only for rules of valid type and pattern
+		valid := true
+		n := 0
+		t, err := rt.ParseRuleType(rule.Type)
+		if err != nil {
+			fail(entry, "%s", err)
+			valid = false
+		}
+		if len(rule.Pattern) == 0 {
+			fail(entry, "missing pattern")
+		} else if t == rt.RuleRegex {
+			if re, err := regexp.Compile(rule.Pattern); err != nil {
+				fail(entry, "invalid pattern: %s", err)
+				valid = false
+			} else {
+				n = re.NumSubexp()
+			}
+		}
+		if len(rule.Replacement) == 0 {
+			fail(entry, "missing replacement")
+		}
+		checkPlaceholders(entry, rule.Replacement, known)
+		if valid {
+			groups[rule.ID] = n
+			checkGroups(entry, rule.Replacement, n, fail)
+		}
+	}
+
+	// catalogue entries may localise the rules of the file or the built-in ones
+	for _, rule := range rt.DefaultRules() {
+		ids[rule.ID] = true
+	}
+	locales := make([]string, 0, len(c.Catalogs))
+	for locale := range c.Catalogs {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	for _, locale := range locales {
+		catalog := c.Catalogs[locale]
+		ruleIDs := make([]string, 0, len(catalog))
+		for id := range catalog {
+			ruleIDs = append(ruleIDs, id)
+		}
+		slices.Sort(ruleIDs)
+		for _, id := range ruleIDs {
+			entry := "catalogs." + locale + "." + id
+			if !ids[id] {
+				fail(entry, "unknown rule")
+				continue
+			}
+			checkPlaceholders(entry, catalog[id], known)
+			if n, ok := groups[id]; ok {
+				checkGroups(entry, catalog[id], n, fail)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkGroups function to report references to groups not covered by the pattern, e.g. $2
+func checkGroups(entry string, replacement string, groups int, fail func(string, string, ...interface{})) {
+	for _, m := range groupPattern.FindAllStringSubmatch(replacement, -1) {
+		ref := m[1] + m[2]
+		if n, _ := strconv.Atoi(ref); n > groups {
+			fail(entry, "unknown group reference $%s", ref)
+		}
+	}
+}
+
+// Apply method to add the templates to the given template manager and to register the
+// rules and catalogues to the given translator; nil targets are skipped. The configuration
+// is validated first and applied to each target at once, so nothing is applied in case of
+// issues and concurrent requests never see a partially applied configuration.
+func (c *Config) Apply(templates *RTM.ResponseTemplateManager, translator *rt.Translator) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var next *rt.Translator
+	if translator != nil {
+		next = translator.Clone()
+		if err := c.register(next); err != nil {
+			return err
+		}
+	}
+	if templates != nil {
+		templates.AddTemplates(c.templates(templates))
+	}
+	if next != nil {
+		translator.Set(next)
+	}
+	return nil
+}
+
+// templates method to return the templates of the configuration in plain format by ID,
+// generated by the given template manager
+func (c *Config) templates(rtm *RTM.ResponseTemplateManager) map[string]string {
+	tpls := make(map[string]string, len(c.Templates))
+	for _, tpl := range c.Templates {
+		tpls[tpl.ID] = rtm.GenerateTemplate(tpl.Code, tpl.Description)
+	}
+	return tpls
+}
+
+// register method to register the rules and catalogues of the configuration to the
+// given translator
+func (c *Config) register(translator *rt.Translator) error {
+	for _, rule := range c.Rules {
+		t, err := rt.ParseRuleType(rule.Type)
+		if err != nil {
+			return err
+		}
+		err = translator.Register(rt.Rule{
+			ID:          rule.ID,
+			Type:        t,
+			Pattern:     rule.Pattern,
+			Replacement: rule.Replacement,
+			Priority:    rule.Priority,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for locale, catalog := range c.Catalogs {
+		translator.AddCatalog(locale, catalog)
+	}
+	return nil
+}
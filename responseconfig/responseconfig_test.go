@@ -0,0 +1,266 @@
+package responseconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
+	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlConfig = `placeholders: [DOMAIN]
+templates:
+  - id: test-maintenance
+    code: "421"
+    description: API under maintenance, see {CONNECTION_URL}
+rules:
+  - id: domain-taken
+    type: regex
+    pattern: Object exists; (.+)
+    replacement: The Domain {DOMAIN} is already registered ($1).
+    priority: 10
+catalogs:
+  de:
+    domain-taken: Die Domain {DOMAIN} ist bereits registriert ($1).
+`
+
+const jsonConfig = `{
+  "rules": [
+    {"id": "domain-taken", "type": "prefix", "pattern": "Object exists", "replacement": "Domain taken"}
+  ]
+}`
+
+var takenRaw = "[RESPONSE]\r\ncode = 549\r\ndescription = Object exists; domain example.com\r\nEOF\r\n"
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/rules.yaml": &fstest.MapFile{Data: []byte(yamlConfig)},
+		"config/rules.json": &fstest.MapFile{Data: []byte(jsonConfig)},
+	}
+	cmd := map[string]string{"COMMAND": "AddDomain", "DOMAIN": "example.com"}
+
+	cfg, err := Load(fsys, "config/rules.yaml")
+	if err != nil {
+		t.Fatalf("TestLoad: Expected YAML configuration to be valid, got %v", err)
+	}
+	assert.Len(t, cfg.Templates, 1)
+	assert.Len(t, cfg.Rules, 1)
+
+	rtm := RTM.NewResponseTemplateManager()
+	tr := rt.NewDefaultTranslator()
+	assert.NoError(t, cfg.Apply(rtm, tr))
+	assert.True(t, rtm.HasTemplate("test-maintenance"))
+	assert.Equal(t, "domain-taken", tr.Rules()[0].ID)
+	h := rp.Parse(tr.Translate(takenRaw, cmd))
+	assert.Equal(t, "The Domain example.com is already registered (domain example.com).", h["DESCRIPTION"])
+	h = rp.Parse(tr.TranslateLocale(takenRaw, cmd, "de-AT"))
+	assert.Equal(t, "Die Domain example.com ist bereits registriert (domain example.com).", h["DESCRIPTION"])
+
+	cfg, err = Load(fsys, "config/rules.json")
+	assert.NoError(t, err)
+	tr = rt.NewTranslator()
+	assert.NoError(t, cfg.Apply(nil, tr))
+	h = rp.Parse(tr.Translate(takenRaw, cmd))
+	assert.Equal(t, "Domain taken", h["DESCRIPTION"])
+
+	_, err = Load(fsys, "config/missing.yaml")
+	assert.Error(t, err)
+	_, err = Decode([]byte(jsonConfig), ".toml")
+	assert.Error(t, err)
+	_, err = Decode([]byte("rules:\n  - id: x\n    typ: regex\n"), ".yml")
+	assert.ErrorContains(t, err, "typ")
+	_, err = Decode([]byte(`{"rulez": []}`), ".json")
+	assert.ErrorContains(t, err, "rulez")
+	// empty files are accepted in both formats
+	for _, ext := range []string{".yaml", ".json"} {
+		cfg, err = Decode([]byte(" \n"), ext)
+		assert.NoError(t, err)
+		assert.Empty(t, cfg.Rules)
+	}
+	cfg, err = Decode([]byte("# no entries yet\n"), ".yaml")
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Rules)
+}
+
+func TestApply(t *testing.T) {
+	rtm := RTM.NewResponseTemplateManager()
+	tr := rt.NewDefaultTranslator()
+	cfg := &Config{
+		Templates: []Template{{ID: "test-maintenance", Code: "421", Description: "Maintenance"}},
+		Rules: []Rule{
+			{ID: "domain-taken", Type: "prefix", Pattern: "Object exists", Replacement: "Domain taken"},
+			{ID: "broken", Type: "regex", Pattern: "(", Replacement: "x"},
+		},
+	}
+	// nothing is applied in case of issues
+	var verrs ValidationErrors
+	assert.ErrorAs(t, cfg.Apply(rtm, tr), &verrs)
+	assert.False(t, rtm.HasTemplate("test-maintenance"))
+	assert.Equal(t, rt.DefaultRules(), tr.Rules())
+
+	cfg.Rules = cfg.Rules[:1]
+	assert.NoError(t, cfg.Apply(rtm, tr))
+	assert.True(t, rtm.HasTemplate("test-maintenance"))
+	assert.Len(t, tr.Rules(), 8)
+}
+
+func TestValidate(t *testing.T) {
+	data := `templates:
+  - id: ok
+    code: "200"
+    description: Fine
+  - id: ok
+    code: "20x"
+    description: ""
+  - id: with-command
+    code: "421"
+    description: Command {COMMAND} failed
+rules:
+  - type: regex
+    pattern: "Syntax error ("
+    replacement: Invalid
+  - id: bad-placeholder
+    type: prefix
+    pattern: Object exists
+    replacement: "Domain {DOMAIN} taken"
+  - id: bad-group
+    type: regex
+    pattern: "Object exists; (.+)"
+    replacement: "Taken ($2)"
+  - id: bad-type
+    type: suffix
+    pattern: x
+    replacement: y
+catalogs:
+  de:
+    bad-group: "Vergeben (${1}, {NAME})"
+    domain-locked: Gesperrt
+    unknown: Unbekannt
+`
+	_, err := Decode([]byte(data), ".yaml")
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("TestValidate: Expected validation errors, got %v", err)
+	}
+	msgs := []string{}
+	for _, e := range verrs {
+		msgs = append(msgs, e.Error())
+	}
+	assert.Equal(t, []string{
+		`templates[1] (ok): duplicate id`,
+		`templates[1] (ok): invalid code "20x"`,
+		`templates[1] (ok): missing description`,
+		`templates[2] (with-command): unknown placeholder {COMMAND}`,
+		`rules[0]: missing id`,
+		"rules[0]: invalid pattern: error parsing regexp: missing closing ): `Syntax error (`",
+		`rules[1] (bad-placeholder): unknown placeholder {DOMAIN}`,
+		`rules[2] (bad-group): unknown group reference $2`,
+		`rules[3] (bad-type): unsupported rule type "suffix"`,
+		`catalogs.de.bad-group: unknown placeholder {NAME}`,
+		`catalogs.de.unknown: unknown rule`,
+	}, msgs)
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	cfg, err := Decode([]byte(`templates:
+  - id: httperror
+    code: "421"
+    description: Request to {CONNECTION_URL} failed{HTTPERROR}, try again
+`), ".yaml")
+	assert.NoError(t, err)
+	rtm := RTM.NewResponseTemplateManager()
+	assert.NoError(t, cfg.Apply(rtm, nil))
+	r, err := response.NewResponseWithOptions("httperror|timeout", map[string]string{"COMMAND": "StatusAccount"}, response.Options{
+		Templates:    rtm,
+		Placeholders: map[string]string{"CONNECTION_URL": "https://api"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Request to https://api failed (timeout), try again", r.GetDescription())
+
+	// placeholders without value are removed one by one
+	rtm.AddTemplate("httperror", rtm.GenerateTemplate("421", "Failed{HTTPERROR}, see {CONNECTION_URL} for details"))
+	r, err = response.NewResponseWithOptions("httperror", map[string]string{"COMMAND": "StatusAccount"}, response.Options{Templates: rtm})
+	assert.NoError(t, err)
+	assert.Equal(t, "Failed, see  for details", r.GetDescription())
+}
+
+func TestWatcher(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	// overrides a built-in template and rule
+	data := yamlConfig + `  de-CH:
+    domain-locked: Gesperrt
+`
+	data = strings.Replace(data, "rules:\n", `rules:
+  - id: domain-locked
+    type: prefix
+    pattern: Domain status
+    replacement: Locked
+`, 1)
+	data = strings.Replace(data, "templates:\n", `templates:
+  - id: empty
+    code: "423"
+    description: Nothing
+`, 1)
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := map[string]string{"COMMAND": "AddDomain", "DOMAIN": "example.com"}
+	lockedRaw := "[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n"
+	rtm := RTM.NewResponseTemplateManager()
+	tr := rt.NewTranslator()
+	assert.NoError(t, tr.Register(rt.DefaultRules()[1]))
+	w := NewWatcher(file, rtm, tr)
+
+	applied, err := w.Reload()
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, "Locked", rp.Parse(tr.Translate(lockedRaw, cmd))["DESCRIPTION"])
+	assert.Equal(t, "Gesperrt", rp.Parse(tr.TranslateLocale(lockedRaw, cmd, "de-CH"))["DESCRIPTION"])
+	assert.Contains(t, rtm.GetTemplate("empty"), "Nothing")
+	assert.True(t, rtm.HasTemplate("test-maintenance"))
+	applied, err = w.Reload()
+	assert.NoError(t, err)
+	assert.False(t, applied)
+
+	// invalid changes are rejected as a whole
+	mtime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.WriteFile(file, []byte("rules:\n  - id: other\n    type: regex\n    pattern: \"(\"\n    replacement: x\n"), 0o600))
+	assert.NoError(t, os.Chtimes(file, mtime, mtime))
+	_, err = w.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "domain-taken", tr.Rules()[0].ID)
+
+	// entries removed from the file are removed from the targets, overridden built-ins
+	// are restored
+	mtime = mtime.Add(time.Minute)
+	assert.NoError(t, os.WriteFile(file, []byte("rules:\n  - id: other\n    type: prefix\n    pattern: Object exists\n    replacement: Taken\n"), 0o600))
+	assert.NoError(t, os.Chtimes(file, mtime, mtime))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Watch(ctx, 5*time.Millisecond, func(err error) { t.Errorf("TestWatcher: Unexpected reload error: %v", err) })
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		return rp.Parse(tr.Translate(takenRaw, cmd))["DESCRIPTION"] == "Taken"
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+	rules := tr.Rules()
+	assert.Len(t, rules, 2)
+	assert.Equal(t, rt.DefaultRules()[1], rules[0])
+	assert.Equal(t, "other", rules[1].ID)
+	h := rp.Parse(tr.TranslateLocale(lockedRaw, cmd, "de-CH"))
+	assert.Equal(t, "This Domain is locked. Initiating a Transfer is therefore impossible.", h["DESCRIPTION"])
+	assert.Equal(t, RTM.NewResponseTemplateManager().GetTemplate("empty"), rtm.GetTemplate("empty"))
+	assert.False(t, rtm.HasTemplate("test-maintenance"))
+}
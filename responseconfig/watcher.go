@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package responseconfig
+
+import (
+	"context"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
+)
+
+// Watcher is a struct representing a configuration file applied to a template manager and
+// a translator, reloaded when it changes on disk. Invalid changes are rejected as a whole,
+// so the previously applied configuration stays in effect.
+//
+// The configuration is applied on top of the templates, rules and catalogues the targets
+// cover when the file is loaded first; changes made to the targets in the meantime are
+// dropped by the next reload.
+type Watcher struct {
+	mu         sync.Mutex
+	file       string
+	templates  *RTM.ResponseTemplateManager
+	translator *rt.Translator
+	modTime    time.Time
+	size       int64
+	loaded     bool
+	// baseTemplates and baseTranslator cover the state of the targets before the first load
+	baseTemplates  map[string]string
+	baseTranslator *rt.Translator
+}
+
+// NewWatcher represents the constructor for struct Watcher.
+// Provide the configuration file and the targets to apply it to; nil targets are skipped.
+func NewWatcher(file string, templates *RTM.ResponseTemplateManager, translator *rt.Translator) *Watcher {
+	return &Watcher{
+		file:       file,
+		templates:  templates,
+		translator: translator,
+	}
+}
+
+// Reload method to load and apply the configuration file if it changed since the last
+// load and to return if it was applied. Templates, rules and catalogue entries removed from
+// the file are removed from the targets; built-in ones overridden by the file are restored.
+func (w *Watcher) Reload() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fi, err := os.Stat(w.file)
+	if err != nil {
+		return false, err
+	}
+	if w.loaded && fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return false, nil
+	}
+	// remember the file state also for invalid changes, so they are reported only once
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+	if !w.loaded {
+		if w.templates != nil {
+			w.baseTemplates = w.templates.GetTemplates()
+		}
+		if w.translator != nil {
+			w.baseTranslator = w.translator.Clone()
+		}
+	}
+	w.loaded = true
+	cfg, err := LoadFile(w.file)
+	if err != nil {
+		return false, err
+	}
+
+	var translator *rt.Translator
+	if w.translator != nil {
+		translator = w.baseTranslator.Clone()
+		if err := cfg.register(translator); err != nil {
+			return false, err
+		}
+	}
+	if w.templates != nil {
+		tpls := maps.Clone(w.baseTemplates)
+		maps.Copy(tpls, cfg.templates(w.templates))
+		w.templates.SetTemplates(tpls)
+	}
+	if translator != nil {
+		w.translator.Set(translator)
+	}
+	return true, nil
+}
+
+// Watch method to poll the configuration file for changes in the given interval until the
+// given context is done. Errors of failed reloads are passed to onError if provided.
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
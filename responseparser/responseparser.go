@@ -8,44 +8,149 @@
 package responseparser
 
 import (
-	"regexp"
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Parse method to return plain API response parsed into hash format
 func Parse(r string) map[string]interface{} {
+	// reading from a strings.Reader never fails
+	hash, _ := ParseReader(strings.NewReader(r))
+	return hash
+}
+
+// ParseReader method to return the plain API response read from the given reader parsed
+// into hash format. The response is processed line by line in a single pass, building the
+// property columns directly; only read errors are returned.
+func ParseReader(r io.Reader) (map[string]interface{}, error) {
 	hash := make(map[string]interface{})
-	tmp := strings.Split(strings.ReplaceAll(r, "\r", ""), "\n")
-	p1 := regexp.MustCompile(`^([^\=]*[^\t\= ])[\t ]*=[\t ]*(.*)$`)
-	p2 := regexp.MustCompile(`(?i)^property\[([^\]]*)\]\[([0-9]+)\]`)
 	properties := make(map[string][]string)
-	for _, row := range tmp {
-		m := p1.MatchString(row)
-		if m {
-			groups := p1.FindStringSubmatch(row)
-			property := strings.ToUpper(groups[1])
-			mm := p2.MatchString(property)
-			if mm {
-				groups2 := p2.FindStringSubmatch(property)
-				key := strings.ReplaceAll(strings.ToUpper(groups2[1]), "\\s", "")
-				// idx2 := strconv.Atoi(groups2[2])
-				list := make([]string, len(properties[key]))
-				copy(list, properties[key])
-				pat := regexp.MustCompile(`[\t ]*$`)
-				rep1 := "${1}$2"
-				list = append(list, pat.ReplaceAllString(groups[2], rep1))
-				properties[key] = list
-			} else {
-				val := groups[2]
-				if len(val) > 0 {
-					pat := regexp.MustCompile(`[\t ]*$`)
-					hash[property] = pat.ReplaceAllString(val, "")
-				}
-			}
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			parseLine(line, hash, properties)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return hash, err
 		}
 	}
 	if len(properties) > 0 {
 		hash["PROPERTY"] = properties
 	}
-	return hash
+	return hash, nil
+}
+
+// parseLine function to add the data of the given plain API response line to the hash
+// or to the property columns respectively
+func parseLine(line string, hash map[string]interface{}, properties map[string][]string) {
+	key, val, ok := splitLine(line)
+	if !ok {
+		return
+	}
+	if col, _, ok := propertyKey(key); ok {
+		properties[col] = append(properties[col], strings.TrimRight(val, "\t "))
+		return
+	}
+	if len(val) > 0 {
+		hash[key] = strings.TrimRight(val, "\t ")
+	}
+}
+
+// splitLine function to return the uppercase key and the value of the given plain API
+// response line in format <key> = <value>, the value with leading whitespace removed
+func splitLine(line string) (string, string, bool) {
+	line = trimLine(line)
+	key, val, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false
+	}
+	key = strings.TrimRight(key, "\t ")
+	if len(key) == 0 {
+		return "", "", false
+	}
+	return strings.ToUpper(key), strings.TrimLeft(val, "\t "), true
+}
+
+// trimLine function to remove carriage returns and the line break of the given line
+func trimLine(line string) string {
+	if strings.IndexByte(line, '\r') >= 0 {
+		line = strings.ReplaceAll(line, "\r", "")
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+// propertyKey function to return the column name and the index of the given (uppercase)
+// key in format PROPERTY[<column>][<index>]
+func propertyKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, "PROPERTY[")
+	if !ok {
+		return "", "", false
+	}
+	col, rest, ok := strings.Cut(rest, "]")
+	if !ok || len(rest) < 3 || rest[0] != '[' {
+		return "", "", false
+	}
+	digits := 0
+	for digits+1 < len(rest) && rest[digits+1] >= '0' && rest[digits+1] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits+1 >= len(rest) || rest[digits+1] != ']' {
+		return "", "", false
+	}
+	return strings.ReplaceAll(col, "\\s", ""), rest[1 : digits+1], true
+}
+
+// Serialize method to return the given hash (as returned by Parse) in plain API response
+// format, the inverse of Parse. The output is canonical: code and description come first,
+// followed by the remaining top-level keys and the properties, each sorted by name.
+func Serialize(hash map[string]interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("[RESPONSE]\r\n")
+	keys := make([]string, 0, len(hash))
+	for key := range hash {
+		upper := strings.ToUpper(key)
+		if upper != "PROPERTY" && upper != "CODE" && upper != "DESCRIPTION" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	keys = append([]string{"CODE", "DESCRIPTION"}, keys...)
+	for _, key := range keys {
+		val, ok := hash[key]
+		if !ok {
+			continue
+		}
+		sb.WriteString(strings.ToLower(key))
+		sb.WriteString(" = ")
+		sb.WriteString(fmt.Sprint(val))
+		sb.WriteString("\r\n")
+	}
+	if p, ok := hash["PROPERTY"].(map[string][]string); ok {
+		props := make([]string, 0, len(p))
+		for key := range p {
+			props = append(props, key)
+		}
+		sort.Strings(props)
+		for _, key := range props {
+			for idx, val := range p[key] {
+				sb.WriteString("property[")
+				sb.WriteString(key)
+				sb.WriteString("][")
+				sb.WriteString(strconv.Itoa(idx))
+				sb.WriteString("] = ")
+				sb.WriteString(val)
+				sb.WriteString("\r\n")
+			}
+		}
+	}
+	sb.WriteString("EOF\r\n")
+	return sb.String()
 }
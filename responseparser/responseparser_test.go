@@ -0,0 +1,243 @@
+package responseparser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const listResponse = "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nqueuetime = 0\r\nruntime = 0.007\r\n" +
+	"property[COUNT][0] = 2\r\nproperty[DOMAIN][0] = cnic-ssl-test1.com\r\nproperty[DOMAIN][1] = cnic-ssl-test2.com\r\n" +
+	"property[NOTE][0] = \r\nproperty[NOTE][1] = a = b\r\nEOF\r\n"
+
+func TestSerialize(t *testing.T) {
+	hash := map[string]interface{}{
+		"RUNTIME":     "0.007",
+		"QUEUETIME":   "0",
+		"DESCRIPTION": "Command completed successfully",
+		"CODE":        "200",
+		"PROPERTY": map[string][]string{
+			"NOTE":   {"", "a = b"},
+			"DOMAIN": {"cnic-ssl-test1.com", "cnic-ssl-test2.com"},
+			"COUNT":  {"2"},
+		},
+	}
+	if plain := Serialize(hash); strings.Compare(plain, listResponse) != 0 {
+		t.Errorf("TestSerialize: Expected canonical plain response, got %s", plain)
+	}
+	if plain := Serialize(map[string]interface{}{"CODE": "421"}); strings.Compare(plain, "[RESPONSE]\r\ncode = 421\r\nEOF\r\n") != 0 {
+		t.Errorf("TestSerialize: Expected missing keys to be skipped, got %s", plain)
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	hash := Parse(listResponse)
+	if !reflect.DeepEqual(Parse(Serialize(hash)), hash) {
+		t.Error("TestSerializeRoundTrip: Expected hash to survive a round trip.")
+	}
+	if strings.Compare(Serialize(hash), listResponse) != 0 {
+		t.Error("TestSerializeRoundTrip: Expected canonical plain response to survive a round trip.")
+	}
+	raw := "[RESPONSE]\r\nproperty[domain][0] = example.com\r\ndescription = Command completed successfully\r\ncode = 200\r\nEOF\r\n"
+	expected := "[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nproperty[DOMAIN][0] = example.com\r\nEOF\r\n"
+	if plain := Serialize(Parse(raw)); strings.Compare(plain, expected) != 0 {
+		t.Errorf("TestSerializeRoundTrip: Expected canonical plain response, got %s", plain)
+	}
+}
+
+func TestParse(t *testing.T) {
+	raw := "[RESPONSE]\r\n" +
+		"code = 200\r\n" +
+		"Description\t=\tCommand completed successfully \t\r\n" +
+		"runtime =   \r\n" +
+		"no assignment\r\n" +
+		" = no key\r\n" +
+		"property[domain][0] = example.com  \r\n" +
+		"PROPERTY[Domain][1]=example.net\r\n" +
+		"property[note][0] = a = b\r\n" +
+		"property[note][x] = invalid index\r\n" +
+		"property[empty][0] =\r\n" +
+		"EOF"
+	expected := map[string]interface{}{
+		"CODE":              "200",
+		"DESCRIPTION":       "Command completed successfully",
+		"PROPERTY[NOTE][X]": "invalid index",
+		"PROPERTY": map[string][]string{
+			"DOMAIN": {"example.com", "example.net"},
+			"NOTE":   {"a = b"},
+			"EMPTY":  {""},
+		},
+	}
+	if hash := Parse(raw); !reflect.DeepEqual(hash, expected) {
+		t.Errorf("TestParse: Expected hash not matching, got %v", hash)
+	}
+	if hash := Parse(""); len(hash) != 0 {
+		t.Errorf("TestParse: Expected empty hash, got %v", hash)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestParseReader(t *testing.T) {
+	hash, err := ParseReader(strings.NewReader(listResponse))
+	if err != nil {
+		t.Fatalf("TestParseReader: Expected not to run into error: %v", err)
+	}
+	if !reflect.DeepEqual(hash, Parse(listResponse)) {
+		t.Error("TestParseReader: Expected same result as Parse.")
+	}
+	if _, err := ParseReader(io.MultiReader(strings.NewReader("code = 200\r\n"), failingReader{})); err == nil {
+		t.Error("TestParseReader: Expected read error to be returned.")
+	}
+}
+
+// generateListResponse function to return a plain list response with the given number of rows
+func generateListResponse(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\n")
+	for i := 0; i < rows; i++ {
+		idx := strconv.Itoa(i)
+		sb.WriteString("property[domain][" + idx + "] = example" + idx + ".com\r\n")
+		sb.WriteString("property[expiration date][" + idx + "] = 2024-09-19 10:52:51\r\n")
+		sb.WriteString("property[renewal][" + idx + "] = AUTORENEW\r\n")
+	}
+	sb.WriteString("EOF\r\n")
+	return sb.String()
+}
+
+func benchmarkParse(b *testing.B, rows int) {
+	raw := generateListResponse(rows)
+	b.SetBytes(int64(len(raw)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash := Parse(raw)
+		if len(hash["PROPERTY"].(map[string][]string)["DOMAIN"]) != rows {
+			b.Fatal("unexpected number of rows")
+		}
+	}
+}
+
+func BenchmarkParse10k(b *testing.B) {
+	benchmarkParse(b, 10000)
+}
+
+func BenchmarkParse100k(b *testing.B) {
+	benchmarkParse(b, 100000)
+}
+
+func TestParseStrict(t *testing.T) {
+	hash, err := ParseStrict(listResponse)
+	if err != nil {
+		t.Fatalf("TestParseStrict: Expected not to run into error: %v", err)
+	}
+	if !reflect.DeepEqual(hash, Parse(listResponse)) {
+		t.Error("TestParseStrict: Expected same result as Parse for valid responses.")
+	}
+
+	// indices are honoured and gaps are filled
+	hash, err = ParseStrict("[RESPONSE]\r\ncode = 200\r\nproperty[domain][2] = example.org\r\nproperty[domain][0] = example.com\r\nEOF\r\n")
+	expected := []string{"example.com", "", "example.org"}
+	if !reflect.DeepEqual(hash["PROPERTY"].(map[string][]string)["DOMAIN"], expected) {
+		t.Errorf("TestParseStrict: Expected indices to be honoured, got %v", hash["PROPERTY"])
+	}
+	var perrs ParseErrors
+	if !errors.As(err, &perrs) || len(perrs) != 1 || perrs[0].Line != 4 {
+		t.Errorf("TestParseStrict: Expected out-of-order error at line 4, got %v", err)
+	}
+}
+
+func TestParseStrictErrors(t *testing.T) {
+	testCases := map[string]struct {
+		raw     string
+		line    int
+		reasons []string
+	}{
+		"truncated": {
+			raw:     "[RESPONSE]\r\ncode = 200\r\nproperty[domain][0] = exam",
+			line:    4,
+			reasons: []string{"missing EOF, response truncated"},
+		},
+		"empty": {
+			raw:     "",
+			line:    1,
+			reasons: []string{"missing [RESPONSE] header", "missing EOF, response truncated"},
+		},
+		"missing header": {
+			raw:     "code = 200\r\nEOF\r\n",
+			line:    1,
+			reasons: []string{"missing [RESPONSE] header"},
+		},
+		"content before header": {
+			raw:     "code = 200\r\n[RESPONSE]\r\nEOF\r\n",
+			line:    1,
+			reasons: []string{"content before [RESPONSE] header"},
+		},
+		"content after EOF": {
+			raw:     "[RESPONSE]\r\ncode = 200\r\nEOF\r\ncode = 500\r\n",
+			line:    4,
+			reasons: []string{"unexpected content after EOF"},
+		},
+		"malformed line": {
+			raw:     "[RESPONSE]\r\ncode 200\r\nEOF\r\n",
+			line:    2,
+			reasons: []string{"malformed line"},
+		},
+		"malformed property": {
+			raw:     "[RESPONSE]\r\nproperty[domain][x] = example.com\r\nEOF\r\n",
+			line:    2,
+			reasons: []string{"malformed property PROPERTY[DOMAIN][X]"},
+		},
+		"duplicate key": {
+			raw:     "[RESPONSE]\r\ncode = 200\r\nCODE = 500\r\nEOF\r\n",
+			line:    3,
+			reasons: []string{"duplicate key CODE"},
+		},
+		"duplicate index": {
+			raw:     "[RESPONSE]\r\nproperty[domain][0] = example.com\r\nproperty[domain][0] = example.net\r\nEOF\r\n",
+			line:    3,
+			reasons: []string{"duplicate index 0 of property DOMAIN"},
+		},
+		"out-of-range index": {
+			raw:     "[RESPONSE]\r\nproperty[domain][16777216] = example.com\r\nproperty[domain][0] = example.net\r\nEOF\r\n",
+			line:    2,
+			reasons: []string{"out-of-range index 16777216 of property DOMAIN", "out-of-order index 0 of property DOMAIN after index 16777216"},
+		},
+		"out-of-range index overflow": {
+			raw:     "[RESPONSE]\r\nproperty[domain][9223372036854775807] = example.com\r\nEOF\r\n",
+			line:    2,
+			reasons: []string{"out-of-range index 9223372036854775807 of property DOMAIN"},
+		},
+		"out-of-order index": {
+			raw:     "[RESPONSE]\r\nproperty[domain][5] = example.com\r\nproperty[domain][2] = example.net\r\nEOF\r\n",
+			line:    3,
+			reasons: []string{"out-of-order index 2 of property DOMAIN after index 5"},
+		},
+	}
+	for name, tc := range testCases {
+		_, err := ParseStrict(tc.raw)
+		var perrs ParseErrors
+		if !errors.As(err, &perrs) {
+			t.Errorf("TestParseStrictErrors: Expected parse errors for %s, got %v", name, err)
+			continue
+		}
+		reasons := []string{}
+		for _, perr := range perrs {
+			reasons = append(reasons, perr.Reason)
+		}
+		if !reflect.DeepEqual(reasons, tc.reasons) || perrs[0].Line != tc.line {
+			t.Errorf("TestParseStrictErrors: Unexpected parse errors for %s: %v", name, err)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != tc.line {
+			t.Errorf("TestParseStrictErrors: Expected single parse error to be unwrappable for %s", name)
+		}
+	}
+}
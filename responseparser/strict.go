@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package responseparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseError represents a single issue found by strict parsing
+type ParseError struct {
+	Line   int    // Line represents the 1-based line number of the issue
+	Reason string // Reason describes the issue
+}
+
+// Error method to return the error message
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// ParseErrors represents the list of issues found by strict parsing
+type ParseErrors []*ParseError
+
+// Error method to return the error message covering all issues
+func (e ParseErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid API response: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap method to return the single issues for use with errors.Is and errors.As
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ParseStrict method to return plain API response parsed into hash format, reporting
+// all issues as ParseErrors. See ParseReaderStrict for details.
+func ParseStrict(r string) (map[string]interface{}, error) {
+	return ParseReaderStrict(strings.NewReader(r))
+}
+
+// ParseReaderStrict method to return the plain API response read from the given reader
+// parsed into hash format. Unlike ParseReader it honours the property indices, filling
+// gaps with empty values, and reports the following issues as ParseErrors:
+// missing [RESPONSE] header, missing EOF (e.g. truncated body), content after EOF,
+// malformed lines, duplicate top-level keys, duplicate, out-of-order or out-of-range
+// property indices. Gaps are filled up to a total of twice the number of lines of the
+// response, so that indices out of that range cannot force huge allocations.
+// The hash is returned also in case of issues; read errors are returned as is.
+func ParseReaderStrict(r io.Reader) (map[string]interface{}, error) {
+	p := &strictParser{
+		hash:       make(map[string]interface{}),
+		keys:       make(map[string]bool),
+		properties: make(map[string][]propertyValue),
+		indices:    make(map[string]map[int]bool),
+		last:       make(map[string]int),
+	}
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			p.lineNo++
+			p.parseLine(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return p.result(), err
+		}
+	}
+	if !p.header {
+		p.fail(1, "missing [RESPONSE] header")
+	} else if p.beforeHeader > 0 {
+		p.fail(p.beforeHeader, "content before [RESPONSE] header")
+	}
+	if !p.eof {
+		p.fail(p.lineNo+1, "missing EOF, response truncated")
+	}
+	hash := p.result()
+	if len(p.errs) > 0 {
+		slices.SortStableFunc(p.errs, func(a, b *ParseError) int {
+			return a.Line - b.Line
+		})
+		return hash, p.errs
+	}
+	return hash, nil
+}
+
+// propertyValue represents a property value at its index
+type propertyValue struct {
+	idx  int
+	val  string
+	line int
+}
+
+// strictParser represents the state of strict parsing
+type strictParser struct {
+	hash map[string]interface{}
+	keys map[string]bool
+	// properties covers the property values in order of appearance per column
+	properties map[string][]propertyValue
+	indices    map[string]map[int]bool
+	last       map[string]int
+	lineNo     int
+	header     bool
+	// beforeHeader represents the first line with content before the header
+	beforeHeader int
+	eof          bool
+	errs         ParseErrors
+}
+
+// fail method to record an issue
+func (p *strictParser) fail(line int, format string, args ...interface{}) {
+	p.errs = append(p.errs, &ParseError{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+// result method to return the parsed hash, placing the property values at their indices
+func (p *strictParser) result() map[string]interface{} {
+	if len(p.properties) == 0 {
+		return p.hash
+	}
+	cols := make([]string, 0, len(p.properties))
+	for col := range p.properties {
+		cols = append(cols, col)
+	}
+	slices.Sort(cols)
+	budget := 2 * p.lineNo
+	properties := make(map[string][]string, len(cols))
+	for _, col := range cols {
+		list := []string{}
+		for _, pv := range p.properties[col] {
+			if pv.idx >= len(list) {
+				if pv.idx-len(list) >= budget {
+					p.fail(pv.line, "out-of-range index %d of property %s", pv.idx, col)
+					continue
+				}
+				grow := pv.idx + 1 - len(list)
+				budget -= grow
+				list = append(list, make([]string, grow)...)
+			}
+			list[pv.idx] = pv.val
+		}
+		properties[col] = list
+	}
+	p.hash["PROPERTY"] = properties
+	return p.hash
+}
+
+// parseLine method to process the given line
+func (p *strictParser) parseLine(line string) {
+	trimmed := strings.TrimSpace(trimLine(line))
+	switch {
+	case p.eof:
+		if len(trimmed) > 0 {
+			p.fail(p.lineNo, "unexpected content after EOF")
+		}
+		return
+	case len(trimmed) == 0:
+		return
+	case trimmed == "[RESPONSE]":
+		if p.header {
+			p.fail(p.lineNo, "duplicate [RESPONSE] header")
+		}
+		p.header = true
+		return
+	case trimmed == "EOF":
+		p.eof = true
+		return
+	}
+	if !p.header && p.beforeHeader == 0 {
+		p.beforeHeader = p.lineNo
+	}
+	key, val, ok := splitLine(line)
+	if !ok {
+		p.fail(p.lineNo, "malformed line")
+		return
+	}
+	if col, digits, ok := propertyKey(key); ok {
+		p.addProperty(key, col, digits, strings.TrimRight(val, "\t "))
+		return
+	}
+	if strings.HasPrefix(key, "PROPERTY[") {
+		p.fail(p.lineNo, "malformed property %s", key)
+		return
+	}
+	if p.keys[key] {
+		p.fail(p.lineNo, "duplicate key %s", key)
+		return
+	}
+	p.keys[key] = true
+	if len(val) > 0 {
+		p.hash[key] = strings.TrimRight(val, "\t ")
+	}
+}
+
+// addProperty method to add the given property value at its index
+func (p *strictParser) addProperty(key string, col string, digits string, val string) {
+	idx, err := strconv.Atoi(digits)
+	if !strings.HasSuffix(key, "["+digits+"]") || err != nil {
+		p.fail(p.lineNo, "malformed property %s", key)
+		return
+	}
+	seen, ok := p.indices[col]
+	if !ok {
+		seen = make(map[int]bool)
+		p.indices[col] = seen
+	}
+	if seen[idx] {
+		p.fail(p.lineNo, "duplicate index %d of property %s", idx, col)
+		return
+	}
+	if last, ok := p.last[col]; ok && idx < last {
+		p.fail(p.lineNo, "out-of-order index %d of property %s after index %d", idx, col, last)
+	} else {
+		p.last[col] = idx
+	}
+	seen[idx] = true
+	p.properties[col] = append(p.properties[col], propertyValue{idx: idx, val: val, line: p.lineNo})
+}
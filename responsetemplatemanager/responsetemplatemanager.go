@@ -14,33 +14,50 @@ import (
 )
 
 // ResponseTemplateManager is a struct used to cover basic functionality to work with
-// API response templates.
+// API response templates. It is safe for concurrent use by multiple goroutines.
 type ResponseTemplateManager struct {
+	mu sync.RWMutex
+	// Templates covers the response templates by ID.
+	//
+	// Deprecated: accessing the field directly is not synchronised; use AddTemplate,
+	// LookupTemplate and GetTemplates instead.
 	Templates map[string]string
 }
 
 var instance *ResponseTemplateManager
 var once sync.Once
 
-// GetInstance method to return the responsetemplatemanager singleton instance
+// GetInstance method to return the responsetemplatemanager singleton instance, used by
+// default where no template manager of its own is provided
 func GetInstance() *ResponseTemplateManager {
 	once.Do(func() {
-		instance = &ResponseTemplateManager{
-			Templates: map[string]string{
-				"404":          generateTemplate("421", "Page not found"),
-				"500":          generateTemplate("500", "Internal server error"),
-				"empty":        generateTemplate("423", "Empty API response. Probably unreachable API end point {CONNECTION_URL}"),
-				"error":        generateTemplate("421", "Command failed due to server error. Client should try again"),
-				"expired":      generateTemplate("530", "SESSION NOT FOUND"),
-				"httperror":    generateTemplate("421", "Command failed due to HTTP communication error"),
-				"unauthorized": generateTemplate("530", "Unauthorized"),
-				"invalid":      generateTemplate("423", "Invalid API response. Contact Support"),
-			},
-		}
+		instance = NewResponseTemplateManager()
 	})
 	return instance
 }
 
+// NewResponseTemplateManager represents the constructor for struct ResponseTemplateManager.
+// The returned instance covers the default templates and is independent of the singleton,
+// e.g. to scope custom templates to an APIClient or a test.
+func NewResponseTemplateManager() *ResponseTemplateManager {
+	return &ResponseTemplateManager{
+		Templates: map[string]string{
+			"404":          generateTemplate("421", "Page not found"),
+			"500":          generateTemplate("500", "Internal server error"),
+			"cancelled":    generateTemplate("421", "Command aborted due to cancelled request context"),
+			"empty":        generateTemplate("423", "Empty API response. Probably unreachable API end point {CONNECTION_URL}"),
+			"error":        generateTemplate("421", "Command failed due to server error. Client should try again"),
+			"expired":      generateTemplate("530", "SESSION NOT FOUND"),
+			"httperror":    generateTemplate("421", "Command failed due to HTTP communication error"),
+			"unauthorized": generateTemplate("530", "Unauthorized"),
+			"invalid":      generateTemplate("423", "Invalid API response. Contact Support"),
+
+			// typed commands failing validation, see apiclient.APIClient.DoCommand
+			"invalidcommand": generateTemplate("505", "Invalid command. Missing or invalid parameters"),
+		},
+	}
+}
+
 // generateTemplate method to generate API a response template string
 // for given code and description
 func generateTemplate(code string, description string) string {
@@ -61,20 +78,64 @@ func (rtm *ResponseTemplateManager) GenerateTemplate(code string, description st
 
 // AddTemplate method to add a template to the templates container
 func (rtm *ResponseTemplateManager) AddTemplate(id string, plain string) *ResponseTemplateManager {
+	rtm.mu.Lock()
+	defer rtm.mu.Unlock()
 	rtm.Templates[id] = plain
 	return rtm
 }
 
+// AddTemplates method to add the given templates to the templates container at once
+func (rtm *ResponseTemplateManager) AddTemplates(tpls map[string]string) *ResponseTemplateManager {
+	rtm.mu.Lock()
+	defer rtm.mu.Unlock()
+	for id, plain := range tpls {
+		rtm.Templates[id] = plain
+	}
+	return rtm
+}
+
+// SetTemplates method to replace all templates of the templates container at once by the
+// given ones, e.g. to restore the result of GetTemplates
+func (rtm *ResponseTemplateManager) SetTemplates(tpls map[string]string) *ResponseTemplateManager {
+	templates := make(map[string]string, len(tpls))
+	for id, plain := range tpls {
+		templates[id] = plain
+	}
+	rtm.mu.Lock()
+	defer rtm.mu.Unlock()
+	rtm.Templates = templates
+	return rtm
+}
+
+// RemoveTemplate method to remove a template from the templates container
+func (rtm *ResponseTemplateManager) RemoveTemplate(id string) *ResponseTemplateManager {
+	rtm.mu.Lock()
+	defer rtm.mu.Unlock()
+	delete(rtm.Templates, id)
+	return rtm
+}
+
 // GetTemplate method to get a ResponseTemplate from templates container
 func (rtm *ResponseTemplateManager) GetTemplate(id string) string {
-	if rtm.HasTemplate(id) {
-		return rtm.Templates[id]
+	if tpl, ok := rtm.LookupTemplate(id); ok {
+		return tpl
 	}
 	return generateTemplate("500", "Response Template not found")
 }
 
+// LookupTemplate method to get a ResponseTemplate from templates container and to
+// return if it exists
+func (rtm *ResponseTemplateManager) LookupTemplate(id string) (string, bool) {
+	rtm.mu.RLock()
+	defer rtm.mu.RUnlock()
+	tpl, ok := rtm.Templates[id]
+	return tpl, ok
+}
+
 // GetTemplates method to return a map covering all available response templates
 func (rtm *ResponseTemplateManager) GetTemplates() map[string]string {
+	rtm.mu.RLock()
+	defer rtm.mu.RUnlock()
 	tpls := map[string]string{}
 	for key := range rtm.Templates {
 		tpls[key] = rtm.Templates[key]
@@ -84,10 +145,8 @@ func (rtm *ResponseTemplateManager) GetTemplates() map[string]string {
 
 // HasTemplate method to check if given template id exists in template container
 func (rtm *ResponseTemplateManager) HasTemplate(id string) bool {
-	if _, ok := rtm.Templates[id]; ok {
-		return true
-	}
-	return false
+	_, ok := rtm.LookupTemplate(id)
+	return ok
 }
 
 // IsTemplateMatchHash method to check if given API response hash matches a given
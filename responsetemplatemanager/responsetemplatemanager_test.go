@@ -2,13 +2,15 @@ package responsetemplatemanager
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	RP "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
 )
 
-var rtm = GetInstance()
+var rtm = NewResponseTemplateManager()
 
 func TestMain(m *testing.M) {
 	rtm.AddTemplate(
@@ -37,12 +39,16 @@ func TestGetTemplate(t *testing.T) {
 }
 
 func TestGetTemplates(t *testing.T) {
-	defaultones := []string{"404", "500", "error", "httperror", "empty", "unauthorized", "expired"}
+	defaultones := []string{"404", "500", "error", "httperror", "empty", "unauthorized", "expired", "cancelled", "invalidcommand"}
 	tpls := rtm.GetTemplates()
 	for _, k := range defaultones {
 		if _, ok := tpls[k]; !ok {
 			t.Errorf("TestGetTemplates: Expected default template '%s' to exist.", k)
 		}
+		// the deprecated field still covers the templates
+		if tpls[k] != rtm.Templates[k] {
+			t.Errorf("TestGetTemplates: Expected template '%s' to be covered by the Templates field.", k)
+		}
 	}
 }
 
@@ -60,3 +66,63 @@ func TestIsTemplateMatchPlain(t *testing.T) {
 		t.Error("TestIsTemplateMatchPlain: Expected plain response to match 'empty' response template.")
 	}
 }
+
+func TestNewResponseTemplateManager(t *testing.T) {
+	m := NewResponseTemplateManager()
+	if m == GetInstance() || GetInstance() != GetInstance() {
+		t.Error("TestNewResponseTemplateManager: Expected independent instance next to the singleton")
+	}
+	m.AddTemplate("scoped", m.GenerateTemplate("200", "Scoped"))
+	if !m.HasTemplate("scoped") || GetInstance().HasTemplate("scoped") || rtm.HasTemplate("scoped") {
+		t.Error("TestNewResponseTemplateManager: Expected template to be scoped to its manager")
+	}
+	m.RemoveTemplate("scoped")
+	if m.HasTemplate("scoped") {
+		t.Error("TestNewResponseTemplateManager: Expected template to be removed")
+	}
+	if _, ok := m.LookupTemplate("empty"); !ok {
+		t.Error("TestNewResponseTemplateManager: Expected default templates to exist")
+	}
+}
+
+func TestSetTemplates(t *testing.T) {
+	m := NewResponseTemplateManager()
+	defaults := m.GetTemplates()
+	m.AddTemplates(map[string]string{
+		"empty":  m.GenerateTemplate("423", "Nothing"),
+		"custom": m.GenerateTemplate("200", "Custom"),
+	})
+	if !m.HasTemplate("custom") || m.GetTemplate("empty") == defaults["empty"] {
+		t.Error("TestSetTemplates: Expected templates to be added")
+	}
+	m.SetTemplates(defaults)
+	if m.HasTemplate("custom") || m.GetTemplate("empty") != defaults["empty"] {
+		t.Error("TestSetTemplates: Expected templates to be replaced")
+	}
+	defaults["empty"] = "changed"
+	if m.GetTemplate("empty") == "changed" {
+		t.Error("TestSetTemplates: Expected templates to be copied")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	m := NewResponseTemplateManager()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := "tpl" + strconv.Itoa(i)
+			for j := 0; j < 100; j++ {
+				m.AddTemplate(id, m.GenerateTemplate("200", "OK"))
+				_ = m.GetTemplate(id)
+				_ = m.GetTemplates()
+				m.RemoveTemplate(id)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(m.GetTemplates()) != len(GetInstance().GetTemplates()) {
+		t.Error("TestConcurrentAccess: Expected only the default templates to remain")
+	}
+}
@@ -16,30 +16,37 @@ import (
 type ResponseTranslator struct {
 }
 
-var descriptionRegexMap = map[string]string{
-	// HX | CNR?
-	"Authorization failed; Operation forbidden by ACL": "Authorization failed; Used Command `{COMMAND}` not white-listed by your Access Control List",
-	// CNR
-	"Domain status does not allow for operation":                                          "This Domain is locked. Initiating a Transfer is therefore impossible.",
-	"Authorization failed [Invalid authorization information]":                            "The given Authorization Code is wrong. Initiating a Transfer is therefore impossible.",
-	"Missing required attribute; premium domain name. please provide required parameters": "Confirm the Premium pricing by providing the necessary premium domain price data.",
-}
+var defaultTranslator = NewDefaultTranslator()
 
-var descriptionRegexMapSkipQuote = map[string]string{
-	// all cases goes here that need translation e.g.
-	// HX | CNR?
-	`Invalid attribute value syntax; resource record \[(.+)\]`:                  "Invalid Syntax for DNSZone Resource Record: $1",
-	`Missing required attribute; CLASS(?:=| \[MUST BE )PREMIUM_([\w\+]+)[\s\]]`: "Confirm the Premium pricing by providing the parameter CLASS with the value PREMIUM_$1.",
-	`Syntax error in Parameter DOMAIN \((.+)\)`:                                 "The Domain Name $1 is invalid.",
+// DefaultTranslator function to return the translator used by Translate.
+// Rules registered to it apply to all API responses not using a translator of their own.
+func DefaultTranslator() *Translator {
+	return defaultTranslator
 }
 
-// Translate function for plain api response
+// Translate function for plain api response.
+// The description is rewritten by the first matching rule of the default translator,
+// placeholders like {CONNECTION_URL} are replaced by the given values.
 func Translate(raw string, cmd map[string]string, phs ...map[string]string) string {
-	ph := map[string]string{}
-	if len(phs) > 0 {
-		ph = phs[0]
-	}
+	return defaultTranslator.Translate(raw, cmd, phs...)
+}
+
+// TranslateLocale function for plain api response using the given locale, e.g. "de-CH".
+// Missing translations fall back to the parent language and finally to English.
+func TranslateLocale(raw string, cmd map[string]string, locale string, phs ...map[string]string) string {
+	return defaultTranslator.TranslateLocale(raw, cmd, locale, phs...)
+}
+
+// TranslateDescription function to return the given API response description rewritten
+// by the first matching rule of the default translator in the given locale, together with
+// the ID of the matched rule. See Translator.TranslateDescription for details.
+func TranslateDescription(description string, cmd map[string]string, locale string, ph map[string]string) (string, string) {
+	return defaultTranslator.TranslateDescription(description, cmd, locale, ph)
+}
 
+// resolveTemplate function to return the template of the given manager for empty, erroneous
+// and invalid plain API responses or the given plain API response otherwise
+func resolveTemplate(rtm *RTM.ResponseTemplateManager, raw string) string {
 	httperror := ""
 	newraw := raw
 	if len(raw) == 0 {
@@ -56,58 +63,32 @@ func Translate(raw string, cmd map[string]string, phs ...map[string]string) stri
 	}
 
 	// Explicit call for a static template
-	rtm := RTM.GetInstance()
-	if rtm.HasTemplate(newraw) {
+	if tpl, ok := rtm.LookupTemplate(newraw); ok {
 		// don't use getTemplate as it leads to endless loop as of again
 		// creating a response instance
-		newraw = rtm.Templates[newraw]
+		newraw = tpl
 		if isHTTPError && len(httperror) > 0 {
 			newraw = strings.ReplaceAll(newraw, "{HTTPERROR}", " ("+httperror+")")
 		}
 	}
 
-	if rtm.HasTemplate("invalid") {
+	if invalid, ok := rtm.LookupTemplate("invalid"); ok {
 		// Missing CODE or DESCRIPTION in API Response
 		pattern1 := regexp.MustCompile(`(?i)description[\s]*=`)
 		pattern2 := regexp.MustCompile(`(?i)code[\s]*=`)
 		pattern3 := regexp.MustCompile(`(?i)description[\s]*=\r\n`)
 
 		if pattern1.FindString(newraw) == "" || pattern2.FindString(newraw) == "" || pattern3.FindString(newraw) != "" {
-			newraw = rtm.Templates["invalid"]
-		}
-	}
-
-	// Iterate through the description-to-regex mapping
-	// generic API response description rewrite
-	// Iterate through the description-to-regex mapping with quoted regex
-	data := ""
-	for regex, val := range descriptionRegexMap {
-		// Escape the regex pattern and attempt to find a match
-		escapedRegex := regexp.QuoteMeta(regex)
-		data = FindMatch(escapedRegex, newraw, val, cmd, ph)
-
-		// If a match is found, exit the inner loop
-		if len(data) > 0 {
-			newraw = data
-			break
-		}
-	}
-
-	// Iterate through the description-to-regex mapping without quotes
-	for regex, val := range descriptionRegexMapSkipQuote {
-		data = FindMatch(regex, newraw, ""+val, cmd, ph)
-
-		// If a match is found, exit the inner loop
-		if len(data) > 0 {
-			newraw = data
-			break
+			newraw = invalid
 		}
 	}
-
-	pattern := regexp.MustCompile(`\{.+\}`)
-	return pattern.ReplaceAllString(newraw, "")
+	return newraw
 }
 
+// FindMatch function to rewrite the description of the given plain API response if it
+// starts with a match of the given regular expression.
+//
+// Deprecated: use a Translator and its rules instead.
 func FindMatch(regex string, newraw string, val string, cmd map[string]string, ph map[string]string) string {
 	// match the response for given description
 	// NOTE: we match if the description starts with the given description
@@ -2,10 +2,13 @@ package responsetranslator_test
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/response"
 	rp "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responseparser"
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
 	rt "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetranslator"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTranslate(t *testing.T) {
@@ -73,3 +76,162 @@ func TestTranslate(t *testing.T) {
 		}
 	})
 }
+
+func TestTranslator(t *testing.T) {
+	cmd := map[string]string{"COMMAND": "CheckDomain", "DOMAIN": "example.com"}
+	raw := "[RESPONSE]\r\ncode = 505\r\ndescription = Syntax error in Parameter DOMAIN (my–domain.de)\r\nEOF\r\n"
+
+	t.Run("DefaultRules", func(t *testing.T) {
+		ids := []string{}
+		for _, rule := range rt.DefaultTranslator().Rules() {
+			ids = append(ids, rule.ID)
+		}
+		assert.Equal(t, []string{"acl-forbidden", "domain-locked", "invalid-auth-code", "premium-price-data", "dnszone-rr-syntax", "premium-class", "invalid-domain-name"}, ids)
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		tr := rt.NewDefaultTranslator()
+		// all of the built-in rule and both added rules match, the one registered first wins
+		assert.NoError(t, tr.Register(rt.Rule{ID: "a", Type: rt.RulePrefix, Pattern: "Syntax error", Replacement: "first"}))
+		assert.NoError(t, tr.Register(rt.Rule{ID: "b", Type: rt.RuleRegex, Pattern: `Syntax error.*`, Replacement: "second"}))
+		expected := tr.Translate(raw, cmd)
+		assert.Contains(t, expected, "description=The Domain Name my–domain.de is invalid.\r\n")
+		for i := 0; i < 100; i++ {
+			if newraw := tr.Translate(raw, cmd); newraw != expected {
+				t.Fatalf("TestTranslator: translation %d differs: %s", i, newraw)
+			}
+		}
+	})
+
+	t.Run("Priority", func(t *testing.T) {
+		tr := rt.NewDefaultTranslator()
+		assert.NoError(t, tr.Register(rt.Rule{ID: "low", Type: rt.RulePrefix, Pattern: "syntax error", Replacement: "low {COMMAND}", Priority: 1}))
+		assert.NoError(t, tr.Register(rt.Rule{ID: "high", Type: rt.RuleRegex, Pattern: `Syntax error in Parameter (\w+)`, Replacement: "high $1 {DOMAIN}", Priority: 2}))
+		h := rp.Parse(tr.Translate(raw, cmd))
+		assert.Equal(t, "high DOMAIN example.com", h["DESCRIPTION"])
+
+		assert.True(t, tr.Remove("high"))
+		assert.False(t, tr.Remove("high"))
+		h = rp.Parse(tr.Translate(raw, cmd))
+		assert.Equal(t, "low CheckDomain", h["DESCRIPTION"])
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		tr := rt.NewDefaultTranslator()
+		assert.NoError(t, tr.Register(rt.Rule{ID: "invalid-domain-name", Type: rt.RuleRegex, Pattern: `Syntax error in Parameter DOMAIN \((.+)\)`, Replacement: "Invalid domain $1."}))
+		rules := tr.Rules()
+		assert.Len(t, rules, 7)
+		assert.Equal(t, "invalid-domain-name", rules[6].ID)
+		h := rp.Parse(tr.Translate(raw, cmd))
+		assert.Equal(t, "Invalid domain my–domain.de.", h["DESCRIPTION"])
+		// the default translator is left untouched
+		h = rp.Parse(rt.Translate(raw, cmd))
+		assert.Equal(t, "The Domain Name my–domain.de is invalid.", h["DESCRIPTION"])
+	})
+
+	t.Run("Exact", func(t *testing.T) {
+		tr := rt.NewTranslator()
+		assert.NoError(t, tr.Register(rt.Rule{ID: "ok", Type: rt.RuleExact, Pattern: "command completed successfully", Replacement: "Done"}))
+		h := rp.Parse(tr.Translate("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully\r\nEOF\r\n", cmd))
+		assert.Equal(t, "Done", h["DESCRIPTION"])
+		h = rp.Parse(tr.Translate("[RESPONSE]\r\ncode = 200\r\ndescription = Command completed successfully; 1 domain\r\nEOF\r\n", cmd))
+		assert.Equal(t, "Command completed successfully; 1 domain", h["DESCRIPTION"])
+	})
+
+	t.Run("CloneAndSet", func(t *testing.T) {
+		tr := rt.NewDefaultTranslator()
+		next := tr.Clone()
+		assert.True(t, next.Remove("invalid-domain-name"))
+		next.AddCatalog("de", rt.Catalog{"domain-locked": "Gesperrt"})
+		assert.Len(t, tr.Rules(), 7)
+		assert.Equal(t, len(rt.DefaultRules()), len(tr.Rules()))
+		tr.Set(next)
+		assert.Equal(t, next.Rules(), tr.Rules())
+		h := rp.Parse(tr.TranslateLocale("[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n", cmd, "de"))
+		assert.Equal(t, "Gesperrt", h["DESCRIPTION"])
+		// the given translator stays independent
+		assert.NoError(t, next.Register(rt.Rule{ID: "x", Type: rt.RulePrefix, Pattern: "x", Replacement: "y"}))
+		assert.Len(t, tr.Rules(), 6)
+	})
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		tr := rt.NewTranslator()
+		assert.Error(t, tr.Register(rt.Rule{Type: rt.RulePrefix, Pattern: "x"}))
+		assert.Error(t, tr.Register(rt.Rule{ID: "x", Type: rt.RulePrefix}))
+		assert.Error(t, tr.Register(rt.Rule{ID: "x", Type: rt.RuleRegex, Pattern: "("}))
+		assert.Error(t, tr.Register(rt.Rule{ID: "x", Type: rt.RuleType(42), Pattern: "x"}))
+		assert.Empty(t, tr.Rules())
+	})
+}
+
+func TestLocaleChain(t *testing.T) {
+	assert.Equal(t, []string{"de-ch", "de", "en"}, rt.LocaleChain("de-CH"))
+	assert.Equal(t, []string{"de-ch", "de", "en"}, rt.LocaleChain("de_CH"))
+	assert.Equal(t, []string{"en-us", "en"}, rt.LocaleChain("en-US"))
+	assert.Equal(t, []string{"en"}, rt.LocaleChain(""))
+}
+
+func TestCatalogs(t *testing.T) {
+	cmd := map[string]string{"COMMAND": "TransferDomain"}
+	raw := "[RESPONSE]\r\ncode = 530\r\ndescription = Authorization failed; Operation forbidden by ACL\r\nEOF\r\n"
+
+	assert.Equal(t, []string{"de", "es", "fr"}, rt.DefaultTranslator().Locales())
+	h := rp.Parse(rt.TranslateLocale(raw, cmd, "fr"))
+	assert.Equal(t, "Échec de l'autorisation ; la commande utilisée `TransferDomain` n'est pas autorisée par votre liste de contrôle d'accès", h["DESCRIPTION"])
+
+	tr := rt.NewDefaultTranslator()
+	tr.AddCatalog("de-CH", rt.Catalog{"acl-forbidden": "Befehl `{COMMAND}` nicht freigegeben"})
+	h = rp.Parse(tr.TranslateLocale(raw, cmd, "de-CH"))
+	assert.Equal(t, "Befehl `TransferDomain` nicht freigegeben", h["DESCRIPTION"])
+	// fallback to de
+	h = rp.Parse(tr.TranslateLocale("[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n", cmd, "de-CH"))
+	assert.Equal(t, "Diese Domain ist gesperrt. Ein Transfer ist daher nicht möglich.", h["DESCRIPTION"])
+
+	fsys := fstest.MapFS{
+		"it.json": &fstest.MapFile{Data: []byte(`{"acl-forbidden": "Comando {COMMAND} non consentito"}`)},
+	}
+	assert.NoError(t, tr.LoadCatalogs(fsys))
+	h = rp.Parse(tr.TranslateLocale(raw, cmd, "it"))
+	assert.Equal(t, "Comando TransferDomain non consentito", h["DESCRIPTION"])
+	// fallback to en
+	h = rp.Parse(tr.TranslateLocale("[RESPONSE]\r\ncode = 219\r\ndescription = Domain status does not allow for operation\r\nEOF\r\n", cmd, "it"))
+	assert.Equal(t, "This Domain is locked. Initiating a Transfer is therefore impossible.", h["DESCRIPTION"])
+
+	err := tr.LoadCatalogs(fstest.MapFS{"nl.json": &fstest.MapFile{Data: []byte(`[]`)}})
+	if err == nil {
+		t.Error("TestCatalogs: Expected invalid catalog to be reported")
+	}
+}
+
+func TestSetResponseTemplateManager(t *testing.T) {
+	tr := rt.NewDefaultTranslator()
+	assert.Same(t, RTM.GetInstance(), tr.GetResponseTemplateManager())
+	rtm := RTM.NewResponseTemplateManager()
+	rtm.AddTemplate("empty", rtm.GenerateTemplate("423", "Nothing returned by {CONNECTION_URL}"))
+	tr.SetResponseTemplateManager(rtm)
+	assert.Same(t, rtm, tr.GetResponseTemplateManager())
+	assert.Same(t, rtm, tr.Clone().GetResponseTemplateManager())
+	h := rp.Parse(tr.TranslateLocale("", map[string]string{}, "de", map[string]string{"CONNECTION_URL": "https://localhost"}))
+	assert.Equal(t, "Nothing returned by https://localhost", h["DESCRIPTION"])
+
+	// the singleton is used by default and left untouched
+	h = rp.Parse(rt.Translate("", map[string]string{}, map[string]string{"CONNECTION_URL": "https://localhost"}))
+	assert.Equal(t, "Empty API response. Probably unreachable API end point https://localhost", h["DESCRIPTION"])
+	tr.SetResponseTemplateManager(nil)
+	assert.Same(t, RTM.GetInstance(), tr.GetResponseTemplateManager())
+}
+
+func TestTranslateDescription(t *testing.T) {
+	cmd := map[string]string{"COMMAND": "AddDomain"}
+	description, ruleID := rt.TranslateDescription("Syntax error in Parameter DOMAIN (my–domain.de)", cmd, "de", nil)
+	assert.Equal(t, "invalid-domain-name", ruleID)
+	assert.Equal(t, "Der Domainname my–domain.de ist ungültig.", description)
+
+	description, ruleID = rt.TranslateDescription("Command completed successfully", cmd, "de", nil)
+	assert.Equal(t, "", ruleID)
+	assert.Equal(t, "Command completed successfully", description)
+
+	// placeholders without value are removed one by one
+	description, _ = rt.TranslateDescription("Failed{HTTPERROR} at {CONNECTION_URL} for {X}", cmd, "", map[string]string{"CONNECTION_URL": "https://localhost"})
+	assert.Equal(t, "Failed at https://localhost for ", description)
+}
@@ -0,0 +1,472 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+package responsetranslator
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	RTM "github.com/centralnicgroup-opensource/rtldev-middleware-go-sdk/v5/responsetemplatemanager"
+)
+
+// DefaultLocale represents the locale of the built-in rule replacements
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog represents the localised replacements of a locale keyed by rule ID
+type Catalog map[string]string
+
+// RuleType represents the way a rule matches the API response description
+type RuleType int
+
+const (
+	// RuleExact matches the whole description (case-insensitive)
+	RuleExact RuleType = iota
+	// RulePrefix matches descriptions starting with the pattern (case-insensitive)
+	RulePrefix
+	// RuleRegex matches descriptions starting with a match of the regular expression
+	// (case-insensitive); the replacement may reference its groups, e.g. $1
+	RuleRegex
+)
+
+// String method to return the name of the rule type
+func (t RuleType) String() string {
+	switch t {
+	case RuleExact:
+		return "exact"
+	case RulePrefix:
+		return "prefix"
+	case RuleRegex:
+		return "regex"
+	}
+	return fmt.Sprintf("RuleType(%d)", int(t))
+}
+
+// ParseRuleType function to return the rule type of the given name, e.g. "regex"
+func ParseRuleType(name string) (RuleType, error) {
+	for _, t := range []RuleType{RuleExact, RulePrefix, RuleRegex} {
+		if strings.EqualFold(name, t.String()) {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported rule type %q", name)
+}
+
+// Rule represents a rule to rewrite the description of API responses. The replacement may
+// contain placeholders like {COMMAND} that are replaced by the values of the API command
+// and of the placeholders provided for translation.
+type Rule struct {
+	ID          string   // ID identifies the rule, registering a rule with an existing ID overrides it
+	Type        RuleType // Type represents the way the rule matches
+	Pattern     string   // Pattern represents the text or regular expression to match
+	Replacement string   // Replacement represents the new description
+	Priority    int      // Priority orders the rules, higher priorities are evaluated first
+}
+
+// compiledRule represents a registered rule
+type compiledRule struct {
+	Rule
+	seq   int
+	regex *regexp.Regexp
+}
+
+// match method to return the submatch indices if the rule matches the given description
+func (r *compiledRule) match(description string) ([]int, bool) {
+	switch r.Type {
+	case RuleExact:
+		if strings.EqualFold(description, r.Pattern) {
+			return nil, true
+		}
+	case RulePrefix:
+		if len(description) >= len(r.Pattern) && strings.EqualFold(description[:len(r.Pattern)], r.Pattern) {
+			return nil, true
+		}
+	case RuleRegex:
+		if m := r.regex.FindStringSubmatchIndex(description); m != nil {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// expand method to return the given replacement with references to the groups of the
+// regular expression, e.g. $1, replaced by the matched text
+func (r *compiledRule) expand(replacement string, description string, m []int) string {
+	if r.regex == nil {
+		return replacement
+	}
+	return string(r.regex.ExpandString(nil, replacement, description, m))
+}
+
+// Translator is a struct representing an ordered list of rules to rewrite API response
+// descriptions. Rules are evaluated by priority (highest first) and then by registration
+// order; the first matching rule wins, so translation is deterministic.
+// It is safe for concurrent use by multiple goroutines.
+//
+// Replacements can be localised by catalogues per locale, see AddCatalog.
+type Translator struct {
+	mu        sync.RWMutex
+	rules     []*compiledRule
+	seq       int
+	catalogs  map[string]Catalog
+	templates *RTM.ResponseTemplateManager
+}
+
+// NewTranslator represents the constructor for struct Translator.
+// The translator starts without rules; see NewDefaultTranslator.
+func NewTranslator() *Translator {
+	return &Translator{
+		rules:    []*compiledRule{},
+		catalogs: map[string]Catalog{},
+	}
+}
+
+// NewDefaultTranslator function to return a new translator covering the built-in rules
+// and their German, French and Spanish catalogues
+func NewDefaultTranslator() *Translator {
+	t := NewTranslator()
+	for _, rule := range defaultRules {
+		if err := t.Register(rule); err != nil {
+			panic(err)
+		}
+	}
+	fsys, err := fs.Sub(locales, "locales")
+	if err == nil {
+		err = t.LoadCatalogs(fsys)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Register method to add the given rule, overriding the rule with the same ID if any.
+// An overridden rule keeps its position among rules of the same priority.
+func (t *Translator) Register(rule Rule) error {
+	if len(rule.ID) == 0 {
+		return fmt.Errorf("invalid rule: missing ID")
+	}
+	cr := &compiledRule{Rule: rule}
+	switch rule.Type {
+	case RuleExact, RulePrefix:
+		if len(rule.Pattern) == 0 {
+			return fmt.Errorf("invalid rule %s: missing pattern", rule.ID)
+		}
+	case RuleRegex:
+		re, err := regexp.Compile(`(?i)^(?:` + rule.Pattern + `)`)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s: %w", rule.ID, err)
+		}
+		cr.regex = re
+	default:
+		return fmt.Errorf("invalid rule %s: unsupported type %s", rule.ID, rule.Type)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	replaced := false
+	for idx, existing := range t.rules {
+		if existing.ID == rule.ID {
+			cr.seq = existing.seq
+			t.rules[idx] = cr
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		cr.seq = t.seq
+		t.seq++
+		t.rules = append(t.rules, cr)
+	}
+	sort.SliceStable(t.rules, func(i, j int) bool {
+		if t.rules[i].Priority != t.rules[j].Priority {
+			return t.rules[i].Priority > t.rules[j].Priority
+		}
+		return t.rules[i].seq < t.rules[j].seq
+	})
+	return nil
+}
+
+// Remove method to remove the rule with the given ID and to return if it existed
+func (t *Translator) Remove(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for idx, existing := range t.rules {
+		if existing.ID == id {
+			t.rules = append(t.rules[:idx], t.rules[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clone method to return an independent copy of the translator covering its rules and
+// catalogues, e.g. to prepare changes applied at once by Set
+func (t *Translator) Clone() *Translator {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	c := &Translator{
+		rules:     slices.Clone(t.rules),
+		seq:       t.seq,
+		catalogs:  make(map[string]Catalog, len(t.catalogs)),
+		templates: t.templates,
+	}
+	for locale, catalog := range t.catalogs {
+		c.catalogs[locale] = maps.Clone(catalog)
+	}
+	return c
+}
+
+// Set method to replace the rules and catalogues of the translator at once by the ones of
+// the given translator, so concurrent translations see either the old or the new state
+func (t *Translator) Set(other *Translator) {
+	c := other.Clone()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rules = c.rules
+	t.seq = c.seq
+	t.catalogs = c.catalogs
+}
+
+// SetResponseTemplateManager method to set the template manager used by Translate and
+// TranslateLocale to resolve templates like "empty" or "invalid", e.g. to scope custom
+// templates to the translator; use nil to reset to the responsetemplatemanager singleton
+func (t *Translator) SetResponseTemplateManager(templates *RTM.ResponseTemplateManager) *Translator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates = templates
+	return t
+}
+
+// GetResponseTemplateManager method to get the template manager used to resolve templates
+func (t *Translator) GetResponseTemplateManager() *RTM.ResponseTemplateManager {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.templates == nil {
+		return RTM.GetInstance()
+	}
+	return t.templates
+}
+
+// Rules method to return the registered rules in evaluation order
+func (t *Translator) Rules() []Rule {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rules := make([]Rule, 0, len(t.rules))
+	for _, r := range t.rules {
+		rules = append(rules, r.Rule)
+	}
+	return rules
+}
+
+// Match method to return the first rule matching the given description and the
+// resulting new description (placeholders not yet replaced)
+func (t *Translator) Match(description string) (Rule, string, bool) {
+	return t.MatchLocale(description, DefaultLocale)
+}
+
+// MatchLocale method to return the first rule matching the given description and the
+// resulting new description in the given locale (placeholders not yet replaced)
+func (t *Translator) MatchLocale(description string, locale string) (Rule, string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, r := range t.rules {
+		if m, ok := r.match(description); ok {
+			return r.Rule, r.expand(t.replacement(r.Rule, locale), description, m), true
+		}
+	}
+	return Rule{}, "", false
+}
+
+// replacement method to return the replacement of the given rule in the given locale
+// following its fallback chain; the rule's replacement is used as last resort
+func (t *Translator) replacement(rule Rule, locale string) string {
+	for _, l := range LocaleChain(locale) {
+		if val, ok := t.catalogs[l][rule.ID]; ok {
+			return val
+		}
+	}
+	return rule.Replacement
+}
+
+// AddCatalog method to add the given localised replacements for the given locale,
+// e.g. "de" or "de-CH". Entries override existing ones of the same rule ID.
+func (t *Translator) AddCatalog(locale string, catalog Catalog) {
+	locale = normalizeLocale(locale)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.catalogs[locale]
+	if !ok {
+		c = Catalog{}
+		t.catalogs[locale] = c
+	}
+	for id, val := range catalog {
+		c[id] = val
+	}
+}
+
+// LoadCatalogs method to add the catalogues of the JSON files in the root of the given
+// file system, e.g. an embed.FS or os.DirFS. The file name represents the locale, e.g.
+// de.json or de-CH.json, the content maps rule IDs to localised replacements.
+func (t *Translator) LoadCatalogs(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		catalog := Catalog{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return fmt.Errorf("invalid catalog %s: %w", file, err)
+		}
+		t.AddCatalog(strings.TrimSuffix(path.Base(file), ".json"), catalog)
+	}
+	return nil
+}
+
+// Locales method to return the locales covered by catalogues
+func (t *Translator) Locales() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	list := make([]string, 0, len(t.catalogs))
+	for locale := range t.catalogs {
+		list = append(list, locale)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// LocaleChain function to return the fallback chain of the given locale ending with the
+// default locale, e.g. ["de-ch", "de", "en"] for "de_CH"
+func LocaleChain(locale string) []string {
+	chain := []string{}
+	for locale = normalizeLocale(locale); len(locale) > 0; {
+		chain = append(chain, locale)
+		idx := strings.LastIndex(locale, "-")
+		if idx < 0 {
+			break
+		}
+		locale = locale[:idx]
+	}
+	if len(chain) == 0 || chain[len(chain)-1] != DefaultLocale {
+		chain = append(chain, DefaultLocale)
+	}
+	return chain
+}
+
+// normalizeLocale function to return the given locale in lowercase using hyphens
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Translate method to return the given plain API response with its description rewritten
+// by the first matching rule. See the package function Translate for details.
+func (t *Translator) Translate(raw string, cmd map[string]string, phs ...map[string]string) string {
+	return t.TranslateLocale(raw, cmd, DefaultLocale, phs...)
+}
+
+// TranslateLocale method to return the given plain API response with its description
+// rewritten by the first matching rule in the given locale
+func (t *Translator) TranslateLocale(raw string, cmd map[string]string, locale string, phs ...map[string]string) string {
+	ph := map[string]string{}
+	if len(phs) > 0 {
+		ph = phs[0]
+	}
+	if ph == nil {
+		ph = map[string]string{}
+	}
+	return t.translate(resolveTemplate(t.GetResponseTemplateManager(), raw), cmd, ph, locale)
+}
+
+var descriptionPattern = regexp.MustCompile(`(?i)description[\s]*=[\s]*([^\r\n]*)`)
+
+var placeholderPattern = regexp.MustCompile(`\{[^}]+\}`)
+
+var remainingPlaceholderPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// translate method to return the translated plain API response
+func (t *Translator) translate(newraw string, cmd map[string]string, ph map[string]string, locale string) string {
+	if m := descriptionPattern.FindStringSubmatchIndex(newraw); m != nil {
+		if _, replacement, ok := t.rewrite(newraw[m[2]:m[3]], cmd, ph, locale); ok {
+			newraw = newraw[:m[0]] + "description=" + replacement + newraw[m[1]:]
+		}
+	}
+	return replacePlaceholders(newraw, ph)
+}
+
+// TranslateDescription method to return the given API response description rewritten by the
+// first matching rule in the given locale, with placeholders like {CONNECTION_URL} replaced by
+// the given values, together with the ID of the matched rule (empty if no rule matched).
+// Unlike Translate it works on an already parsed API response.
+func (t *Translator) TranslateDescription(description string, cmd map[string]string, locale string, ph map[string]string) (string, string) {
+	ruleID := ""
+	if rule, replacement, ok := t.rewrite(description, cmd, ph, locale); ok {
+		ruleID = rule.ID
+		description = replacement
+	}
+	return replacePlaceholders(description, ph), ruleID
+}
+
+// rewrite method to return the first rule matching the given description and its replacement
+// in the given locale with the placeholders of the command and the given ones replaced
+func (t *Translator) rewrite(description string, cmd map[string]string, ph map[string]string, locale string) (Rule, string, bool) {
+	rule, replacement, ok := t.MatchLocale(description, locale)
+	if !ok {
+		return rule, "", false
+	}
+	for key, val := range cmd {
+		replacement = strings.ReplaceAll(replacement, "{"+key+"}", val)
+	}
+	for key, val := range ph {
+		replacement = strings.ReplaceAll(replacement, "{"+key+"}", val)
+	}
+	return rule, replacement, true
+}
+
+// replacePlaceholders function to replace the placeholders of the given text, e.g.
+// {CONNECTION_URL}, by the given values and to remove the remaining ones
+func replacePlaceholders(text string, ph map[string]string) string {
+	text = placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if val, ok := ph[match[1:len(match)-1]]; ok {
+			return val
+		}
+		return match
+	})
+	return remainingPlaceholderPattern.ReplaceAllString(text, "")
+}
+
+// DefaultRules function to return the built-in rules covered by NewDefaultTranslator
+func DefaultRules() []Rule {
+	return slices.Clone(defaultRules)
+}
+
+// defaultRules covers the built-in rules
+var defaultRules = []Rule{
+	// HX | CNR?
+	{ID: "acl-forbidden", Type: RulePrefix, Pattern: "Authorization failed; Operation forbidden by ACL", Replacement: "Authorization failed; Used Command `{COMMAND}` not white-listed by your Access Control List"},
+	// CNR
+	{ID: "domain-locked", Type: RulePrefix, Pattern: "Domain status does not allow for operation", Replacement: "This Domain is locked. Initiating a Transfer is therefore impossible."},
+	{ID: "invalid-auth-code", Type: RulePrefix, Pattern: "Authorization failed [Invalid authorization information]", Replacement: "The given Authorization Code is wrong. Initiating a Transfer is therefore impossible."},
+	{ID: "premium-price-data", Type: RulePrefix, Pattern: "Missing required attribute; premium domain name. please provide required parameters", Replacement: "Confirm the Premium pricing by providing the necessary premium domain price data."},
+	// HX | CNR?
+	{ID: "dnszone-rr-syntax", Type: RuleRegex, Pattern: `Invalid attribute value syntax; resource record \[(.+)\]`, Replacement: "Invalid Syntax for DNSZone Resource Record: $1"},
+	{ID: "premium-class", Type: RuleRegex, Pattern: `Missing required attribute; CLASS(?:=| \[MUST BE )PREMIUM_([\w\+]+)[\s\]]`, Replacement: "Confirm the Premium pricing by providing the parameter CLASS with the value PREMIUM_$1."},
+	{ID: "invalid-domain-name", Type: RuleRegex, Pattern: `Syntax error in Parameter DOMAIN \((.+)\)`, Replacement: "The Domain Name $1 is invalid."},
+}
@@ -7,16 +7,29 @@
 package socketconfig
 
 import (
+	"context"
 	"net/url"
 	"strings"
+	"sync"
 )
 
+// CredentialProvider represents a source of API credentials that is consulted per request,
+// so that rotated credentials are picked up without restarting. The url of the API endpoint
+// in use is provided to allow for looking up credentials per endpoint.
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context, apiURL string) (login string, password string, err error)
+}
+
 // SocketConfig is a struct representing connection settings used as POST data for http request against the insanely fast HEXONET backend API.
+// It is safe for concurrent use by multiple goroutines.
 type SocketConfig struct {
+	mu         sync.RWMutex
 	login      string
 	pw         string
+	otp        string
 	session    string
 	persistent string
+	provider   CredentialProvider
 }
 
 // NewSocketConfig represents the constructor for struct SocketConfig.
@@ -25,14 +38,31 @@ func NewSocketConfig() *SocketConfig {
 		login:      "",
 		persistent: "",
 		pw:         "",
+		otp:        "",
 		session:    "",
 	}
 	return sc
 }
 
+// Clone method to return an independent copy of the connection settings
+func (s *SocketConfig) Clone() *SocketConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SocketConfig{
+		login:      s.login,
+		persistent: s.persistent,
+		pw:         s.pw,
+		otp:        s.otp,
+		session:    s.session,
+		provider:   s.provider,
+	}
+}
+
 // GetPOSTData method to return the struct data ready to submit within
 // POST request of type "application/x-www-form-urlencoded"
 func (s *SocketConfig) GetPOSTData() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var tmp strings.Builder
 	if len(s.login) > 0 {
 		tmp.WriteString(url.QueryEscape("s_login"))
@@ -46,6 +76,12 @@ func (s *SocketConfig) GetPOSTData() string {
 		tmp.WriteString(url.QueryEscape(s.pw))
 		tmp.WriteString("&")
 	}
+	if len(s.otp) > 0 {
+		tmp.WriteString(url.QueryEscape("s_otp"))
+		tmp.WriteString("=")
+		tmp.WriteString(url.QueryEscape(s.otp))
+		tmp.WriteString("&")
+	}
 	if len(s.session) > 0 {
 		tmp.WriteString(url.QueryEscape("s_sessionid"))
 		tmp.WriteString("=")
@@ -63,22 +99,30 @@ func (s *SocketConfig) GetPOSTData() string {
 
 // GetSession method to return the session id currently in use.
 func (s *SocketConfig) GetSession() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.session
 }
 
 // GetLogin method to return the login id currently in use.
 func (s *SocketConfig) GetLogin() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.login
 }
 
 // SetLogin method to set username to use for api communication
 func (s *SocketConfig) SetLogin(value string) *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.login = value
 	return s
 }
 
 // Persistent method for session to use for api communication
 func (s *SocketConfig) SetPersistent() *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.session = ""
 	s.persistent = "1"
 	return s
@@ -86,15 +130,65 @@ func (s *SocketConfig) SetPersistent() *SocketConfig {
 
 // SetPassword method to set password to use for api communication
 func (s *SocketConfig) SetPassword(value string) *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.session = ""
 	s.pw = value
 	return s
 }
 
+// SetOTP method to set the one time password to use for api login of 2FA accounts
+func (s *SocketConfig) SetOTP(value string) *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.otp = value
+	return s
+}
+
+// SetCredentialProvider method to set a provider to consult for credentials instead of
+// static ones; use nil to remove it
+func (s *SocketConfig) SetCredentialProvider(provider CredentialProvider) *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+	return s
+}
+
+// GetCredentialProvider method to return the credential provider in use
+func (s *SocketConfig) GetCredentialProvider() CredentialProvider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.provider
+}
+
+// ResolveCredentials method to apply the credentials returned by the credential provider
+// for the given API url. Nothing is done if no provider is set or a session is in use.
+func (s *SocketConfig) ResolveCredentials(ctx context.Context, apiURL string) error {
+	s.mu.RLock()
+	provider := s.provider
+	session := s.session
+	s.mu.RUnlock()
+	if provider == nil || len(session) > 0 {
+		return nil
+	}
+	login, password, err := provider.GetCredentials(ctx, apiURL)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.login = login
+	s.pw = password
+	return nil
+}
+
 // SetSession method to set a API session id to use for api communication instead of credentials
 // which is basically required in case you plan to use session based communication or if you want to use 2FA
 func (s *SocketConfig) SetSession(sessionid string) *SocketConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.pw = ""
+	s.otp = ""
 	s.persistent = ""
 	s.session = sessionid
 	return s
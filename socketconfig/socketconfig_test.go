@@ -1,6 +1,8 @@
 package socketconfig
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -11,3 +13,63 @@ func TestGetPOSTData(t *testing.T) {
 		t.Error("TestGetPOSTData: Expected postdata string should be empty.")
 	}
 }
+
+func TestClone(t *testing.T) {
+	scfg := NewSocketConfig()
+	scfg.SetLogin("myaccountid")
+	scfg.SetPassword("mypassword")
+	clone := scfg.Clone()
+	clone.SetPersistent()
+	clone.SetSession("mysession")
+	if strings.Compare(scfg.GetPOSTData(), "s_login=myaccountid&s_pw=mypassword&") != 0 {
+		t.Error("TestClone: Expected original postdata not to be affected by changes to the clone.")
+	}
+	if strings.Compare(clone.GetPOSTData(), "s_login=myaccountid&s_sessionid=mysession&") != 0 {
+		t.Error("TestClone: Expected cloned postdata string not matching.")
+	}
+}
+
+func TestSetOTP(t *testing.T) {
+	scfg := NewSocketConfig()
+	scfg.SetLogin("myaccountid").SetPassword("mypassword").SetOTP("123456")
+	if strings.Compare(scfg.GetPOSTData(), "s_login=myaccountid&s_pw=mypassword&s_otp=123456&") != 0 {
+		t.Error("TestSetOTP: Expected postdata string not matching.")
+	}
+	scfg.SetSession("mysession")
+	if strings.Compare(scfg.GetPOSTData(), "s_login=myaccountid&s_sessionid=mysession&") != 0 {
+		t.Error("TestSetOTP: Expected one time password to be reset with session.")
+	}
+}
+
+type staticProvider struct {
+	login    string
+	password string
+	err      error
+}
+
+func (p staticProvider) GetCredentials(_ context.Context, _ string) (string, string, error) {
+	return p.login, p.password, p.err
+}
+
+func TestResolveCredentials(t *testing.T) {
+	scfg := NewSocketConfig()
+	if err := scfg.ResolveCredentials(context.Background(), ""); err != nil {
+		t.Error("TestResolveCredentials: Expected no error without credential provider.")
+	}
+	scfg.SetCredentialProvider(staticProvider{login: "myaccountid", password: "mypassword"})
+	if err := scfg.ResolveCredentials(context.Background(), ""); err != nil {
+		t.Error("TestResolveCredentials: Expected no error for resolvable credentials.")
+	}
+	if strings.Compare(scfg.GetPOSTData(), "s_login=myaccountid&s_pw=mypassword&") != 0 {
+		t.Error("TestResolveCredentials: Expected postdata string not matching.")
+	}
+	scfg.SetSession("mysession")
+	scfg.SetCredentialProvider(staticProvider{err: errors.New("secret unavailable")})
+	if err := scfg.ResolveCredentials(context.Background(), ""); err != nil {
+		t.Error("TestResolveCredentials: Expected provider not to be consulted while a session is in use.")
+	}
+	scfg.SetSession("")
+	if err := scfg.ResolveCredentials(context.Background(), ""); err == nil {
+		t.Error("TestResolveCredentials: Expected provider error to be returned.")
+	}
+}
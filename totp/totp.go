@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Kai Schwarz (HEXONET GmbH). All rights reserved.
+//
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE.md file.
+
+// Package totp provides a generator for time-based one time passwords (RFC 6238)
+// to be used for API login of 2FA accounts in headless services
+package totp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // mandated by RFC 6238 default algorithm
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Digits represents the number of digits of a generated one time password
+const Digits = 6
+
+// Period represents the time step of a generated one time password
+const Period = 30 * time.Second
+
+// Generate function to return the one time password for the given base32 encoded
+// shared secret at the given point in time
+func Generate(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	counter := uint64(t.Unix() / int64(Period/time.Second)) //nolint:gosec // unix time is positive
+	return generateCode(key, counter, Digits), nil
+}
+
+// Provider function to return a one time password provider for the given base32 encoded
+// shared secret, e.g. to be used as apiclient.ReloginOptions.OTPProvider
+func Provider(secret string) func(ctx context.Context) (string, error) {
+	return func(_ context.Context) (string, error) {
+		return Generate(secret, time.Now())
+	}
+}
+
+// decodeSecret function to decode the given base32 encoded shared secret
+// (case-insensitive, spaces and padding are optional)
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 encoded secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("invalid base32 encoded secret: empty")
+	}
+	return key, nil
+}
+
+// generateCode function to return the HMAC-based one time password (RFC 4226)
+// for the given key and counter
+func generateCode(key []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	code %= uint32(math.Pow10(digits))
+	return fmt.Sprintf("%0*d", digits, code)
+}
@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// secret represents the RFC 6238 test secret "12345678901234567890" base32 encoded
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerate(t *testing.T) {
+	testCases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, expected := range testCases {
+		code, err := Generate(secret, time.Unix(ts, 0))
+		if err != nil {
+			t.Fatalf("TestGenerate: Expected not to run into error: %v", err)
+		}
+		if code != expected {
+			t.Errorf("TestGenerate: Expected code %s at %d, got %s", expected, ts, code)
+		}
+	}
+}
+
+func TestGenerateNormalizesSecret(t *testing.T) {
+	code, err := Generate("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+	if err != nil || code != "287082" {
+		t.Errorf("TestGenerateNormalizesSecret: Expected code 287082, got %s (%v)", code, err)
+	}
+}
+
+func TestGenerateInvalidSecret(t *testing.T) {
+	if _, err := Generate("not base32!", time.Now()); err == nil {
+		t.Error("TestGenerateInvalidSecret: Expected error for invalid secret.")
+	}
+	if _, err := Generate("", time.Now()); err == nil {
+		t.Error("TestGenerateInvalidSecret: Expected error for empty secret.")
+	}
+}
+
+func TestProvider(t *testing.T) {
+	code, err := Provider(secret)(context.Background())
+	if err != nil || len(code) != Digits {
+		t.Errorf("TestProvider: Expected %d digit code, got %q (%v)", Digits, code, err)
+	}
+}